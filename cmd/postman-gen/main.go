@@ -5,8 +5,19 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 
+	"github.com/williamkoller/postman-gen/internal/bruno"
+	"github.com/williamkoller/postman-gen/internal/curl"
+	"github.com/williamkoller/postman-gen/internal/docs"
+	"github.com/williamkoller/postman-gen/internal/har"
+	"github.com/williamkoller/postman-gen/internal/httpfile"
+	"github.com/williamkoller/postman-gen/internal/hurl"
+	"github.com/williamkoller/postman-gen/internal/insomnia"
+	"github.com/williamkoller/postman-gen/internal/k6"
+	"github.com/williamkoller/postman-gen/internal/openapi"
 	"github.com/williamkoller/postman-gen/internal/postman"
 	"github.com/williamkoller/postman-gen/internal/scan"
 )
@@ -23,21 +34,62 @@ func main() {
 	buildTags := flag.String("build-tags", "", "Build tags (e.g.: \"dev,integration\") for typed analysis")
 	envOut := flag.String("env-out", "", "Postman Environment output file (optional)")
 	envName := flag.String("env-name", "Local", "Name of the Postman Environment")
+	format := flag.String("format", "postman", "Output format: postman, openapi (YAML, or JSON when -out ends in .json), insomnia, bruno (-out is a directory), http, hurl, k6, curl, markdown, html or har")
+	split := flag.Bool("split", false, "Write one .http file per top-level folder (-format http; -out is a directory)")
+	k6VUs := flag.Int("k6-vus", 10, "Virtual users of the k6 scenario (-format k6)")
+	k6Duration := flag.String("k6-duration", "30s", "Duration of the k6 scenario (-format k6)")
+	k6ReadOnly := flag.Bool("k6-read-only", false, "Run only GET/HEAD endpoints by default (-format k6)")
+	specFile := flag.String("spec", "", "OpenAPI document (YAML or JSON) whose summaries, parameters and examples enrich matching endpoints")
+	minimalBody := flag.Bool("minimal-body", false, "Leave omitempty and omitzero fields out of generated body examples")
+	typesFile := flag.String("types", "", "JSON file mapping in-house types to their JSON example, e.g. {\"money.Money\": {\"example\": \"10.00 EUR\"}}")
+	authSpec := flag.String("auth", "", "Collection auth, e.g. bearer:{{token}}, basic:{{user}}:{{pass}}, apikey:X-API-Key:{{apiKey}}, noauth")
 	flag.Parse()
 
+	var collectionAuth *scan.AuthInfo
+	if *authSpec != "" {
+		a, err := scan.ParseAuthSpec(*authSpec)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error parsing -auth: %v\n", err)
+			os.Exit(2)
+		}
+		collectionAuth = a
+	}
+
+	var spec *openapi.Spec
+	if *specFile != "" {
+		s, err := openapi.LoadSpec(*specFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error reading -spec %s: %v\n", *specFile, err)
+			os.Exit(2)
+		}
+		spec = s
+	}
+
+	if *typesFile != "" {
+		if err := scan.LoadWellKnownTypes(*typesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "error reading -types %s: %v\n", *typesFile, err)
+			os.Exit(2)
+		}
+	}
+
 	var endpoints []scan.Endpoint
 	var err error
+	report := func(d scan.Diagnostic) {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", d)
+	}
 
 	if *useTypes {
 		endpoints, _ = scan.ScanDirWithOpts(scan.ScanOptions{
-			Dir:       *dir,
-			UseTypes:  true,
-			BuildTags: *buildTags,
+			Dir:         *dir,
+			UseTypes:    true,
+			BuildTags:   *buildTags,
+			MinimalBody: *minimalBody,
+			Report:      report,
 		})
 	}
 
 	if len(endpoints) == 0 { // fallback (or -use-types=false)
-		endpoints, err = scan.ScanDir(*dir)
+		endpoints, err = scan.ScanDirWithOpts(scan.ScanOptions{Dir: *dir, MinimalBody: *minimalBody, Report: report})
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error scanning %s: %v\n", *dir, err)
 			os.Exit(1)
@@ -48,6 +100,10 @@ func main() {
 		fmt.Fprintln(os.Stderr, "No endpoints found. Tip: use @route for dynamic routes.")
 	}
 
+	if spec != nil {
+		printSpecReport(*specFile, spec.Enrich(endpoints))
+	}
+
 	sort.Slice(endpoints, func(i, j int) bool {
 		if endpoints[i].Path == endpoints[j].Path {
 			if endpoints[i].Method == endpoints[j].Method {
@@ -58,13 +114,103 @@ func main() {
 		return endpoints[i].Path < endpoints[j].Path
 	})
 
-	col := postman.BuildCollection(postman.BuildOpts{
+	buildOpts := postman.BuildOpts{
 		Name:          *name,
 		BaseURL:       *baseURL,
 		GroupDepth:    *groupDepth,
 		GroupByMethod: *groupByMethod,
 		TagFolders:    *tagFolders,
-	}, endpoints)
+		Auth:          collectionAuth,
+	}
+
+	switch *format {
+	case "postman":
+	case "openapi":
+		opts := openapi.Options{Title: *name, ServerURL: *baseURL}
+		if analysis, err := scan.AnalyzeProject(*dir); err == nil {
+			opts.Structs, opts.Types, opts.Constants = analysis.Structs, analysis.Types, analysis.Constants
+		}
+		doc := openapi.Build(opts, endpoints)
+		var data []byte
+		if strings.HasSuffix(strings.ToLower(*out), ".json") {
+			data, err = doc.MarshalIndent()
+		} else {
+			data, err = doc.MarshalYAML()
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializing OpenAPI document: %v\n", err)
+			os.Exit(1)
+		}
+		writeOutput(*out, data, "OpenAPI document")
+		return
+	case "insomnia":
+		data, err := json.MarshalIndent(insomnia.Build(buildOpts, endpoints), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializing Insomnia export: %v\n", err)
+			os.Exit(1)
+		}
+		writeOutput(*out, data, "Insomnia export")
+		return
+	case "bruno":
+		if *out == "" {
+			fmt.Fprintln(os.Stderr, "error: -format bruno requires -out <dir>")
+			os.Exit(2)
+		}
+		if err := bruno.WriteDir(*out, bruno.Build(buildOpts, *envName, endpoints)); err != nil {
+			fmt.Fprintf(os.Stderr, "error writing Bruno collection: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	case "http":
+		writeHTTPFiles(buildOpts, endpoints, *split, *out, *envOut, *envName)
+		return
+	case "hurl":
+		writeOutput(*out, []byte(hurl.Build(buildOpts, endpoints)), "Hurl file")
+		if *envOut != "" {
+			col := postman.BuildCollection(buildOpts, endpoints)
+			env := postman.CollectionEnvironment(*envName, *baseURL, col)
+			writeOutput(*envOut, []byte(hurl.Variables(env)), "Hurl variables file")
+		}
+		return
+	case "k6":
+		script := k6.Build(buildOpts, k6.Options{VUs: *k6VUs, Duration: *k6Duration, ReadOnly: *k6ReadOnly}, endpoints)
+		writeOutput(*out, []byte(script), "k6 script")
+		return
+	case "curl":
+		script := []byte(curl.Build(buildOpts, endpoints))
+		writeOutput(*out, script, "curl script")
+		if *out != "" {
+			// The script is meant to be run directly
+			_ = os.Chmod(*out, 0o755)
+		}
+		return
+	case "markdown":
+		writeOutput(*out, []byte(docs.Build(buildOpts, endpoints).RelativeTo(*dir).Markdown()), "Markdown reference")
+		return
+	case "html":
+		page, err := docs.Build(buildOpts, endpoints).RelativeTo(*dir).HTML()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error rendering HTML reference: %v\n", err)
+			os.Exit(1)
+		}
+		writeOutput(*out, []byte(page), "HTML reference")
+		return
+	case "har":
+		col := postman.BuildCollection(buildOpts, endpoints)
+		env := postman.CollectionEnvironment(*envName, *baseURL, col)
+		data, err := json.MarshalIndent(har.Build(buildOpts, env, endpoints), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error serializing HAR log: %v\n", err)
+			os.Exit(1)
+		}
+		writeOutput(*out, data, "HAR log")
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown -format %q (want postman, openapi, insomnia, bruno, http, hurl, k6, curl, markdown, html or har)\n", *format)
+		os.Exit(2)
+	}
+
+	col := postman.BuildCollection(buildOpts, endpoints)
 
 	data, err := json.MarshalIndent(col, "", "  ")
 	if err != nil {
@@ -72,17 +218,10 @@ func main() {
 		os.Exit(1)
 	}
 
-	if *out == "" {
-		fmt.Println(string(data))
-	} else {
-		if err := os.WriteFile(*out, data, 0o644); err != nil {
-			fmt.Fprintf(os.Stderr, "error writing Collection: %v\n", err)
-			os.Exit(1)
-		}
-	}
+	writeOutput(*out, data, "Collection")
 
 	if *envOut != "" {
-		env := postman.BuildEnvironment(*envName, *baseURL)
+		env := postman.CollectionEnvironment(*envName, *baseURL, col)
 		edata, err := json.MarshalIndent(env, "", "  ")
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "error serializing Environment: %v\n", err)
@@ -94,3 +233,70 @@ func main() {
 		}
 	}
 }
+
+// writeHTTPFiles writes the .http output and, unless it goes to stdout,
+// an http-client.env.json next to it (or at -env-out)
+func writeHTTPFiles(opts postman.BuildOpts, endpoints []scan.Endpoint, split bool, out, envOut, envName string) {
+	files := httpfile.Build(opts, split, endpoints)
+	envDir := filepath.Dir(out)
+	switch {
+	case split:
+		if out == "" {
+			fmt.Fprintln(os.Stderr, "error: -split requires -out <dir>")
+			os.Exit(2)
+		}
+		if err := os.MkdirAll(out, 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "error creating %s: %v\n", out, err)
+			os.Exit(1)
+		}
+		for _, f := range files {
+			writeOutput(filepath.Join(out, f.Path), []byte(f.Content), "HTTP file")
+		}
+		envDir = out
+	default:
+		writeOutput(out, []byte(files[0].Content), "HTTP file")
+	}
+
+	if envOut == "" {
+		if out == "" {
+			return
+		}
+		envOut = filepath.Join(envDir, "http-client.env.json")
+	}
+	env := postman.CollectionEnvironment(envName, opts.BaseURL, postman.BuildCollection(opts, endpoints))
+	data, err := httpfile.Environment(env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error serializing Environment: %v\n", err)
+		os.Exit(1)
+	}
+	writeOutput(envOut, data, "Environment")
+}
+
+// printSpecReport lists the routes only the spec or only the code knows
+func printSpecReport(path string, r openapi.SpecReport) {
+	fmt.Fprintf(os.Stderr, "%s: %d operations matched\n", path, r.Matched)
+	if len(r.SpecOnly) > 0 {
+		fmt.Fprintf(os.Stderr, "In the spec but not found in code (%d):\n", len(r.SpecOnly))
+		for _, route := range r.SpecOnly {
+			fmt.Fprintf(os.Stderr, "  %s\n", route)
+		}
+	}
+	if len(r.CodeOnly) > 0 {
+		fmt.Fprintf(os.Stderr, "In code but not in the spec (%d):\n", len(r.CodeOnly))
+		for _, route := range r.CodeOnly {
+			fmt.Fprintf(os.Stderr, "  %s\n", route)
+		}
+	}
+}
+
+// writeOutput writes data to path, or to stdout when path is empty
+func writeOutput(path string, data []byte, what string) {
+	if path == "" {
+		fmt.Println(string(data))
+		return
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "error writing %s: %v\n", what, err)
+		os.Exit(1)
+	}
+}
@@ -1,3 +1,13 @@
 module github.com/williamkoller/postman-gen
 
 go 1.24.5
+
+require (
+	golang.org/x/tools v0.42.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/mod v0.33.0 // indirect
+	golang.org/x/sync v0.19.0 // indirect
+)
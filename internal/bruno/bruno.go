@@ -0,0 +1,295 @@
+// Package bruno writes scanned endpoints as a Bruno collection: a
+// bruno.json, one directory per folder of the Postman tree, one .bru file
+// per request and environments/*.bru. Output contains no ids or
+// timestamps, so regenerating an unchanged API rewrites identical files.
+package bruno
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// File is a file of the collection, relative to the output directory
+type File struct {
+	Path    string
+	Content string
+}
+
+// Build converts eps into collection files grouped like
+// postman.BuildCollection with the same options; the environment is
+// built by postman.CollectionEnvironment and named envName
+func Build(opts postman.BuildOpts, envName string, eps []scan.Endpoint) []File {
+	col := postman.BuildCollection(opts, eps)
+
+	config, _ := json.MarshalIndent(map[string]interface{}{
+		"version": "1",
+		"name":    opts.Name,
+		"type":    "collection",
+		"ignore":  []string{"node_modules", ".git"},
+	}, "", "  ")
+	files := []File{{Path: "bruno.json", Content: string(config) + "\n"}}
+
+	files = items(files, "", col.Item, col.Auth)
+
+	env := postman.CollectionEnvironment(envName, opts.BaseURL, col)
+	files = append(files, environmentFile(env))
+	return files
+}
+
+// WriteDir writes files below dir, creating directories as needed. The
+// directory belongs to the generator: .bru files that files no longer
+// contain (removed endpoints, folders or environments) are deleted, along
+// with the directories left empty.
+func WriteDir(dir string, files []File) error {
+	if err := removeStale(dir, files); err != nil {
+		return err
+	}
+	for _, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(f.Content), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// items appends a folder.bru per folder and a .bru per request below dir.
+// Auth a request would inherit in Postman is resolved onto the request.
+func items(files []File, dir string, list []postman.Item, inherited *postman.Auth) []File {
+	used := make(map[string]bool)
+	for i, it := range list {
+		seq := i + 1
+		if it.Request == nil {
+			sub := joinPath(dir, uniqueName(used, fileName(it.Name)))
+			var b strings.Builder
+			fmt.Fprintf(&b, "meta {\n  name: %s\n  seq: %d\n}\n", it.Name, seq)
+			files = append(files, File{Path: sub + "/folder.bru", Content: b.String()})
+
+			files = items(files, sub, it.Item, it.ResolveAuth(inherited))
+			continue
+		}
+
+		name := uniqueName(used, strings.ToLower(fileName(it.Name)))
+		files = append(files, File{
+			Path:    joinPath(dir, name+".bru"),
+			Content: requestFile(it.Name, seq, it.Request, it.ResolveAuth(inherited)),
+		})
+	}
+	return files
+}
+
+// removeStale deletes the .bru files below dir that are not in files
+func removeStale(dir string, files []File) error {
+	keep := make(map[string]bool, len(files))
+	for _, f := range files {
+		keep[filepath.Join(dir, filepath.FromSlash(f.Path))] = true
+	}
+	var dirs []string
+	touched := make(map[string]bool) // directories that lost an entry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == dir {
+			return fs.SkipAll // nothing written yet
+		}
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || d.Name() == "node_modules" {
+				return fs.SkipDir
+			}
+			if path != dir {
+				dirs = append(dirs, path)
+			}
+			return nil
+		}
+		if filepath.Ext(path) == ".bru" && !keep[path] {
+			touched[filepath.Dir(path)] = true
+			return os.Remove(path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Deepest first, so parents emptied by their children go too; Remove
+	// fails on directories that still hold something, which keeps them
+	for i := len(dirs) - 1; i >= 0; i-- {
+		if touched[dirs[i]] && os.Remove(dirs[i]) == nil {
+			touched[filepath.Dir(dirs[i])] = true
+		}
+	}
+	return nil
+}
+
+var bruMethods = map[string]bool{
+	"get": true, "post": true, "put": true, "delete": true,
+	"patch": true, "options": true, "head": true,
+}
+
+func requestFile(name string, seq int, r *postman.Request, auth *postman.Auth) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "meta {\n  name: %s\n  type: http\n  seq: %d\n}\n", name, seq)
+
+	// Routes registered for any method are sent as GET
+	method := strings.ToLower(r.Method)
+	if !bruMethods[method] {
+		method = "get"
+	}
+	bodyMode := "none"
+	if r.Body != nil && r.Body.Raw != "" {
+		bodyMode = "json"
+	}
+	authMode, authText := authBlock(auth)
+	fmt.Fprintf(&b, "\n%s {\n  url: %s\n  body: %s\n  auth: %s\n}\n",
+		method, strings.SplitN(r.URL.Raw, "?", 2)[0], bodyMode, authMode)
+
+	if len(r.URL.Query) > 0 {
+		b.WriteString("\nparams:query {\n")
+		for _, q := range r.URL.Query {
+			prefix := ""
+			if q.Disabled {
+				prefix = "~"
+			}
+			fmt.Fprintf(&b, "  %s%s: %s\n", prefix, q.Key, q.Value)
+		}
+		b.WriteString("}\n")
+	}
+
+	if len(r.URL.Variable) > 0 {
+		b.WriteString("\nparams:path {\n")
+		for _, v := range r.URL.Variable {
+			fmt.Fprintf(&b, "  %s: %s\n", v.Key, v.Value)
+		}
+		b.WriteString("}\n")
+	}
+
+	if len(r.Header) > 0 {
+		// The collection keeps annotation headers in map order
+		headers := append([]postman.Header(nil), r.Header...)
+		sort.SliceStable(headers, func(i, j int) bool { return headers[i].Key < headers[j].Key })
+		b.WriteString("\nheaders {\n")
+		for _, h := range headers {
+			fmt.Fprintf(&b, "  %s: %s\n", h.Key, h.Value)
+		}
+		b.WriteString("}\n")
+	}
+
+	b.WriteString(authText)
+
+	if bodyMode == "json" {
+		body := r.Body.Raw
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, []byte(body), "", "  ") == nil {
+			body = pretty.String()
+		}
+		b.WriteString("\nbody:json {\n" + indent(body) + "}\n")
+	}
+
+	if r.Description != "" {
+		b.WriteString("\ndocs {\n" + indent(r.Description) + "}\n")
+	}
+	return b.String()
+}
+
+// authBlock returns the auth mode of the method block and the matching
+// auth:<mode> block; oauth2 access tokens are sent as bearer tokens
+func authBlock(a *postman.Auth) (string, string) {
+	if a == nil {
+		return "none", ""
+	}
+	block := func(mode string, pairs ...string) (string, string) {
+		var b strings.Builder
+		fmt.Fprintf(&b, "\nauth:%s {\n", mode)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			fmt.Fprintf(&b, "  %s: %s\n", pairs[i], pairs[i+1])
+		}
+		b.WriteString("}\n")
+		return mode, b.String()
+	}
+	m := a.Attrs()
+	switch a.Type {
+	case "bearer":
+		return block("bearer", "token", m["token"])
+	case "basic":
+		return block("basic", "username", m["username"], "password", m["password"])
+	case "digest":
+		return block("digest", "username", m["username"], "password", m["password"])
+	case "apikey":
+		return block("apikey", "key", m["key"], "value", m["value"], "placement", "header")
+	case "oauth2":
+		return block("bearer", "token", m["accessToken"])
+	}
+	return "none", ""
+}
+
+// environmentFile renders env as environments/<name>.bru, listing secret
+// values under vars:secret so Bruno never stores them in the file
+func environmentFile(env postman.Environment) File {
+	var b strings.Builder
+	var secrets []string
+	b.WriteString("vars {\n")
+	for _, v := range env.Values {
+		if v.Type == "secret" {
+			secrets = append(secrets, v.Key)
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: %s\n", v.Key, v.Value)
+	}
+	b.WriteString("}\n")
+	if len(secrets) > 0 {
+		b.WriteString("\nvars:secret [\n  " + strings.Join(secrets, ",\n  ") + "\n]\n")
+	}
+	return File{Path: "environments/" + fileName(env.Name) + ".bru", Content: b.String()}
+}
+
+func indent(s string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(s, "\n"), "\n") {
+		b.WriteString("  " + line + "\n")
+	}
+	return b.String()
+}
+
+func joinPath(dir, name string) string {
+	if dir == "" {
+		return name
+	}
+	return dir + "/" + name
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName turns an item name like "GET /v1/users/:id" into a portable
+// file name ("GET-v1-users-id")
+func fileName(name string) string {
+	s := strings.Trim(unsafeName.ReplaceAllString(name, "-"), "-.")
+	if s == "" {
+		return "item"
+	}
+	return s
+}
+
+// uniqueName suffixes -2, -3... to names already used in the same directory
+func uniqueName(used map[string]bool, name string) string {
+	candidate := name
+	for n := 2; used[strings.ToLower(candidate)]; n++ {
+		candidate = name + "-" + strconv.Itoa(n)
+	}
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
@@ -0,0 +1,130 @@
+package bruno
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func TestBuild(t *testing.T) {
+	eps := []scan.Endpoint{
+		{Method: "GET", Path: "/users/{id}", Query: []scan.QueryParam{{Name: "fields"}}},
+		{Method: "POST", Path: "/users", BodyRaw: `{"name":"string"}`, Auth: &scan.AuthInfo{Type: "bearer"}},
+		{Method: "GET", Path: "/health"},
+	}
+	opts := postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080", GroupDepth: 1}
+	files := Build(opts, "Local", eps)
+
+	byPath := make(map[string]string)
+	var paths []string
+	for _, f := range files {
+		byPath[f.Path] = f.Content
+		paths = append(paths, f.Path)
+	}
+	want := []string{
+		"bruno.json",
+		"health/folder.bru",
+		"health/get-health.bru",
+		"users/folder.bru",
+		"users/post-users.bru",
+		"users/get-users-id.bru",
+		"environments/Local.bru",
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Fatalf("unexpected files:\n got %v\nwant %v", paths, want)
+	}
+
+	get := byPath["users/get-users-id.bru"]
+	for _, s := range []string{
+		"meta {\n  name: GET /users/{id}\n  type: http\n  seq: 2\n}",
+		"get {\n  url: {{baseUrl}}/users/:id\n  body: none\n  auth: none\n}",
+		"params:query {\n  ~fields: \n}",
+		"params:path {\n  id: 1\n}",
+	} {
+		if !strings.Contains(get, s) {
+			t.Errorf("GET request missing %q:\n%s", s, get)
+		}
+	}
+
+	post := byPath["users/post-users.bru"]
+	for _, s := range []string{
+		"auth: bearer",
+		"auth:bearer {\n  token: {{token}}\n}",
+		"headers {\n  Content-Type: application/json\n}",
+		"body:json {\n  {\n    \"name\": \"string\"\n  }\n}",
+	} {
+		if !strings.Contains(post, s) {
+			t.Errorf("POST request missing %q:\n%s", s, post)
+		}
+	}
+
+	env := byPath["environments/Local.bru"]
+	if env != "vars {\n  baseUrl: http://localhost:8080\n}\n\nvars:secret [\n  token\n]\n" {
+		t.Errorf("unexpected environment:\n%s", env)
+	}
+
+	if again := Build(opts, "Local", eps); !reflect.DeepEqual(files, again) {
+		t.Error("output is not deterministic")
+	}
+}
+
+func TestFileName(t *testing.T) {
+	used := make(map[string]bool)
+	if got := uniqueName(used, fileName("GET /v1/users/:id")); got != "GET-v1-users-id" {
+		t.Errorf("unexpected name %q", got)
+	}
+	if got := uniqueName(used, fileName("GET /v1/users/{id}")); got != "GET-v1-users-id-2" {
+		t.Errorf("expected collision suffix, got %q", got)
+	}
+	if got := fileName("/"); got != "item" {
+		t.Errorf("expected fallback name, got %q", got)
+	}
+}
+
+func TestWriteDir_RemovesStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	opts := postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080", GroupDepth: 1}
+	first := Build(opts, "Local", []scan.Endpoint{
+		{Method: "GET", Path: "/users"},
+		{Method: "GET", Path: "/health"},
+	})
+	if err := WriteDir(dir, first); err != nil {
+		t.Fatal(err)
+	}
+	notes := filepath.Join(dir, "health", "NOTES.md")
+	if err := os.WriteFile(notes, []byte("kept"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	second := Build(opts, "Local", []scan.Endpoint{{Method: "GET", Path: "/users"}})
+	if err := WriteDir(dir, second); err != nil {
+		t.Fatal(err)
+	}
+	for _, gone := range []string{"health/get-health.bru", "health/folder.bru"} {
+		if _, err := os.Stat(filepath.Join(dir, gone)); !os.IsNotExist(err) {
+			t.Errorf("%s: expected the stale file to be removed", gone)
+		}
+	}
+	if _, err := os.Stat(notes); err != nil {
+		t.Errorf("files other than .bru must be kept: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users", "get-users.bru")); err != nil {
+		t.Error(err)
+	}
+
+	// a folder left empty goes with its files
+	if err := os.Remove(notes); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteDir(dir, Build(opts, "Local", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "users")); !os.IsNotExist(err) {
+		t.Error("expected the emptied users directory to be removed")
+	}
+}
@@ -0,0 +1,240 @@
+// Package curl renders scanned endpoints as a self-contained bash script
+// with one curl function per endpoint and a `list` subcommand printing
+// the catalogue.
+package curl
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+type command struct {
+	fn   string
+	args []string
+	name string
+	code string
+	vars []string
+}
+
+// Build renders the script, with endpoints in the order of the Postman tree
+// built with the same options. Every {{variable}} becomes an environment
+// variable (baseUrl → BASE_URL, token → TOKEN) and path variables become
+// positional arguments defaulting to their example values.
+func Build(opts postman.BuildOpts, eps []scan.Endpoint) string {
+	col := postman.BuildCollection(opts, eps)
+
+	g := &generator{used: make(map[string]bool)}
+	postman.Walk(col.Item, col.Auth, g.command)
+
+	vars := map[string]bool{"baseUrl": true}
+	for _, c := range g.commands {
+		for _, v := range c.vars {
+			vars[v] = true
+		}
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("#!/usr/bin/env bash\n")
+	b.WriteString("# Generated by postman-gen.\n")
+	b.WriteString("#   ./api.sh list\n")
+	b.WriteString("#   BASE_URL=https://api.example.com TOKEN=... ./api.sh <command> [path args...] [curl args...]\n")
+	b.WriteString("set -euo pipefail\n\n")
+	for _, name := range names {
+		def := ""
+		if name == "baseUrl" {
+			def = opts.BaseURL
+		}
+		env := postman.EnvName(name)
+		fmt.Fprintf(&b, "%s=\"${%s:-%s}\"\n", env, env, doubleQuoted(def))
+	}
+
+	for _, c := range g.commands {
+		b.WriteString("\n" + c.code)
+	}
+
+	b.WriteString("\nlist() {\n  cat <<'CATALOGUE'\n")
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 4, 2, ' ', 0)
+	for _, c := range g.commands {
+		usage := c.fn
+		for _, a := range c.args {
+			usage += " [" + a + "]"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", usage, c.name)
+	}
+	tw.Flush()
+	b.WriteString(table.String())
+	b.WriteString("CATALOGUE\n}\n\n")
+
+	b.WriteString("cmd=\"${1:-list}\"\n")
+	b.WriteString("shift || true\n")
+	b.WriteString("case \"$cmd\" in\n")
+	b.WriteString("  list | help | -h | --help)\n    list\n    ;;\n")
+	if len(g.commands) > 0 {
+		fns := make([]string, len(g.commands))
+		for i, c := range g.commands {
+			fns[i] = c.fn
+		}
+		b.WriteString("  " + strings.Join(fns, " | \\\n  ") + ")\n")
+		b.WriteString("    \"$cmd\" \"$@\"\n    ;;\n")
+	}
+	b.WriteString("  *)\n    echo \"unknown command: $cmd (run '$0 list')\" >&2\n    exit 1\n    ;;\n")
+	b.WriteString("esac\n")
+	return b.String()
+}
+
+type generator struct {
+	commands []command
+	used     map[string]bool
+}
+
+func (g *generator) command(it postman.Item, auth *postman.Auth) {
+	r := it.Request
+
+	// Routes registered for any method are sent as GET
+	method := strings.ToUpper(r.Method)
+	if method == "ANY" || method == "" {
+		method = http.MethodGet
+	}
+
+	rawURL := strings.SplitN(r.URL.Raw, "?", 2)[0]
+	fn := funcName(method, rawURL)
+	base := fn
+	for n := 2; g.used[fn]; n++ {
+		fn = base + "_" + strconv.Itoa(n)
+	}
+	g.used[fn] = true
+
+	c := command{fn: fn, name: it.Name}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", it.Name)
+	fmt.Fprintf(&b, "%s() {\n", fn)
+
+	// Path variables are positional arguments; whatever follows them is
+	// passed on to curl. Every :segment of the URL gets one, declared in
+	// the collection or not, so none is left in the URL.
+	vars := append([]postman.Variable(nil), r.URL.Variable...)
+	declared := make(map[string]bool)
+	for _, v := range vars {
+		declared[v.Key] = true
+	}
+	for _, name := range postman.PathVariables(rawURL) {
+		if !declared[name] {
+			declared[name] = true
+			vars = append(vars, postman.Variable{Key: name, Value: name})
+		}
+	}
+	locals := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, v := range vars {
+		local := shellName(v.Key)
+		for base, n := local, 2; taken[local]; n++ {
+			local = base + "_" + strconv.Itoa(n)
+		}
+		taken[local] = true
+		locals[v.Key] = local
+		c.args = append(c.args, v.Key)
+		fmt.Fprintf(&b, "  local %s=\"${1:-%s}\"\n", local, doubleQuoted(v.Value))
+		b.WriteString("  shift || true\n")
+	}
+
+	url := postman.ExpandPath(doubleQuoted(rawURL), func(name string) string {
+		if local, ok := locals[name]; ok {
+			return "${" + local + "}"
+		}
+		return ":" + name
+	})
+	fmt.Fprintf(&b, "  curl -sS -X %s \"%s\"", method, c.expand(url))
+
+	// The collection keeps annotation headers in map order
+	headers := append([]postman.Header(nil), r.Header...)
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Key < headers[j].Key })
+	for _, arg := range c.authArgs(auth) {
+		b.WriteString(" \\\n    " + arg)
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&b, " \\\n    -H \"%s\"", c.expand(doubleQuoted(h.Key+": "+h.Value)))
+	}
+	if r.Body != nil && r.Body.Raw != "" {
+		b.WriteString(" \\\n    --data " + singleQuoted(r.Body.Raw))
+	}
+	b.WriteString(" \\\n    \"$@\"\n}\n")
+
+	c.code = b.String()
+	g.commands = append(g.commands, c)
+}
+
+// authArgs returns the curl arguments sending auth
+func (c *command) authArgs(a *postman.Auth) []string {
+	if a == nil {
+		return nil
+	}
+	quote := func(s string) string { return "\"" + c.expand(doubleQuoted(s)) + "\"" }
+	m := a.Attrs()
+	switch a.Type {
+	case "bearer":
+		return []string{"-H " + quote("Authorization: Bearer "+m["token"])}
+	case "oauth2":
+		return []string{"-H " + quote("Authorization: Bearer "+m["accessToken"])}
+	case "apikey":
+		return []string{"-H " + quote(m["key"]+": "+m["value"])}
+	case "basic":
+		return []string{"-u " + quote(m["username"]+":"+m["password"])}
+	case "digest":
+		return []string{"--digest", "-u " + quote(m["username"]+":"+m["password"])}
+	}
+	return nil
+}
+
+var (
+	variableRe = regexp.MustCompile(`\{\{([A-Za-z0-9_]+)\}\}`)
+	nonIdent   = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// expand replaces {{var}} references in an already escaped string with
+// their environment variables and records them
+func (c *command) expand(s string) string {
+	return variableRe.ReplaceAllStringFunc(s, func(ref string) string {
+		name := variableRe.FindStringSubmatch(ref)[1]
+		c.vars = append(c.vars, name)
+		return "${" + postman.EnvName(name) + "}"
+	})
+}
+
+// doubleQuoted escapes s for use inside double quotes
+func doubleQuoted(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(s)
+}
+
+func singleQuoted(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellName makes a path variable usable as a local variable name
+func shellName(name string) string {
+	s := strings.Trim(nonIdent.ReplaceAllString(name, "_"), "_")
+	if s == "" || unicode.IsDigit(rune(s[0])) {
+		s = "p_" + s
+	}
+	return s
+}
+
+// funcName builds the command name from the method and path,
+// e.g. get_v1_users_id for GET {{baseUrl}}/v1/users/:id
+func funcName(method, rawURL string) string {
+	return strings.ToLower(strings.Join(postman.RouteWords(method, rawURL), "_"))
+}
@@ -0,0 +1,68 @@
+package curl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func TestBuild(t *testing.T) {
+	eps := []scan.Endpoint{
+		{Method: "GET", Path: "/v1/users/{id}", RequestHeaders: []scan.HeaderParam{{Name: "X-Tenant-ID"}}},
+		{Method: "POST", Path: "/v1/users", BodyRaw: `{"name":"O'Brien"}`, Auth: &scan.AuthInfo{Type: "bearer"}},
+		{Method: "DELETE", Path: "/v1/orgs/{org}/users/{id}", Auth: &scan.AuthInfo{Type: "basic"}},
+	}
+	got := Build(postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080"}, eps)
+
+	for _, s := range []string{
+		"#!/usr/bin/env bash\n",
+		"BASE_URL=\"${BASE_URL:-http://localhost:8080}\"\nPASSWORD=\"${PASSWORD:-}\"\nTENANT_ID=\"${TENANT_ID:-}\"\nTOKEN=\"${TOKEN:-}\"\nUSERNAME=\"${USERNAME:-}\"\n",
+		"# GET /v1/users/{id}\nget_v1_users_id() {\n  local id=\"${1:-1}\"\n  shift || true\n" +
+			"  curl -sS -X GET \"${BASE_URL}/v1/users/${id}\" \\\n    -H \"X-Tenant-ID: ${TENANT_ID}\" \\\n    \"$@\"\n}\n",
+		"post_v1_users() {\n  curl -sS -X POST \"${BASE_URL}/v1/users\" \\\n    -H \"Authorization: Bearer ${TOKEN}\" \\\n" +
+			"    -H \"Content-Type: application/json\" \\\n    --data '{\"name\":\"O'\\''Brien\"}' \\\n",
+		"  local org=\"${1:-org}\"\n  shift || true\n  local id=\"${1:-1}\"\n",
+		"-u \"${USERNAME}:${PASSWORD}\"",
+		"delete_v1_orgs_org_users_id [org] [id]  DELETE /v1/orgs/{org}/users/{id}\n",
+		"  list | help | -h | --help)\n",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q in:\n%s", s, got)
+		}
+	}
+}
+
+func TestBuild_PathVariablesSubstituted(t *testing.T) {
+	eps := []scan.Endpoint{
+		{Method: "PUT", Path: "/orders/:order_id/items/:item-id"},
+		{Method: "GET", Path: "/a/:item-id/b/:item_id"},
+	}
+	got := Build(postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080"}, eps)
+
+	for _, s := range []string{
+		"  local order_id=\"${1:-1}\"\n  shift || true\n  local item_id=\"${1:-1}\"\n",
+		"curl -sS -X PUT \"${BASE_URL}/orders/${order_id}/items/${item_id}\"",
+		"  local item_id_2=\"${1:-1}\"\n",
+		"curl -sS -X GET \"${BASE_URL}/a/${item_id}/b/${item_id_2}\"",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q in:\n%s", s, got)
+		}
+	}
+	for _, line := range strings.Split(got, "\n") {
+		if strings.Contains(line, "curl -sS") && strings.Contains(line, "/:") {
+			t.Errorf("path variable left in URL: %s", line)
+		}
+	}
+}
+
+func TestFuncName(t *testing.T) {
+	if got := funcName("GET", "{{baseUrl}}/v1/users/:id"); got != "get_v1_users_id" {
+		t.Errorf("funcName = %q", got)
+	}
+	if got := funcName("GET", "{{baseUrl}}/"); got != "get" {
+		t.Errorf("funcName = %q", got)
+	}
+}
@@ -0,0 +1,265 @@
+// Package docs renders an API reference in Markdown or as a single
+// self-contained HTML page. Sections follow the folder tree of the
+// Postman collection built with the same options.
+package docs
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// Reference is the rendered model shared by the Markdown and HTML output
+type Reference struct {
+	Title     string
+	BaseURL   string
+	Endpoints []Endpoint // requests outside any folder
+	Sections  []Section
+}
+
+// Section is a folder of the collection tree
+type Section struct {
+	Name      string
+	Level     int // 1 for top-level folders
+	Endpoints []Endpoint
+	Sections  []Section
+}
+
+// Endpoint is the documentation of a single request
+type Endpoint struct {
+	ID          string // anchor, e.g. get-v1-users-id
+	Title       string // "GET /v1/users/{id}"
+	Method      string
+	Path        string
+	Description string
+	Handler     string
+	Source      string // file:line
+	SourceFile  string
+	Line        int
+	Auth        string
+	Middleware  []string
+	PathParams  []Param
+	Query       []Param
+	Headers     []Param
+	Body        string
+	BodyType    string
+	BodyEnums   []Param // body fields with a fixed set of values: Value is the type, Description the values
+	Responses   []Response
+}
+
+type Param struct {
+	Name        string
+	Value       string
+	Description string
+}
+
+type Response struct {
+	Status      string // "201 Created"
+	ContentType string
+	Type        string
+	Body        string
+}
+
+// Build groups eps like postman.BuildCollection does with the same options
+func Build(opts postman.BuildOpts, eps []scan.Endpoint) *Reference {
+	col := postman.BuildCollection(opts, eps)
+
+	b := &builder{byName: make(map[string][]scan.Endpoint), ids: make(map[string]bool)}
+	for _, e := range eps {
+		name := strings.TrimSpace(strings.ToUpper(e.Method) + " " + e.Path)
+		b.byName[name] = append(b.byName[name], e)
+	}
+
+	ref := &Reference{Title: opts.Name, BaseURL: opts.BaseURL}
+	ref.Endpoints, ref.Sections = b.items(col.Item, col.Auth, 1)
+	return ref
+}
+
+// RelativeTo rewrites source files relative to root, the scanned
+// directory, so links work wherever the reference is published. Files
+// outside root are left as they are.
+func (r *Reference) RelativeTo(root string) *Reference {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return r
+	}
+	relativeEndpoints(r.Endpoints, absRoot)
+	relativeSections(r.Sections, absRoot)
+	return r
+}
+
+func relativeSections(sections []Section, root string) {
+	for _, s := range sections {
+		relativeEndpoints(s.Endpoints, root)
+		relativeSections(s.Sections, root)
+	}
+}
+
+func relativeEndpoints(endpoints []Endpoint, root string) {
+	for i := range endpoints {
+		e := &endpoints[i]
+		if e.SourceFile == "" {
+			continue
+		}
+		abs, err := filepath.Abs(e.SourceFile)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		e.SourceFile = filepath.ToSlash(rel)
+		e.Source = sourceText(e.SourceFile, e.Line)
+	}
+}
+
+// sourceText is file:line
+func sourceText(file string, line int) string {
+	if line > 0 {
+		return file + ":" + strconv.Itoa(line)
+	}
+	return file
+}
+
+type builder struct {
+	byName map[string][]scan.Endpoint
+	ids    map[string]bool
+}
+
+func (b *builder) items(items []postman.Item, inherited *postman.Auth, level int) ([]Endpoint, []Section) {
+	var endpoints []Endpoint
+	var sections []Section
+	for _, it := range items {
+		if it.Request == nil {
+			s := Section{Name: it.Name, Level: level}
+			s.Endpoints, s.Sections = b.items(it.Item, it.ResolveAuth(inherited), level+1)
+			sections = append(sections, s)
+			continue
+		}
+		endpoints = append(endpoints, b.endpoint(it, it.ResolveAuth(inherited)))
+	}
+	return endpoints, sections
+}
+
+// endpoint combines the scanned endpoint behind a collection item with
+// the request the collection built for it
+func (b *builder) endpoint(it postman.Item, auth *postman.Auth) Endpoint {
+	r := it.Request
+	e := b.scanned(it.Name, r)
+
+	doc := Endpoint{
+		ID:          b.anchor(it.Name),
+		Title:       it.Name,
+		Method:      r.Method,
+		Path:        e.Path,
+		Description: e.Desc,
+		Handler:     e.Handler,
+		SourceFile:  e.SourceFile,
+		Line:        e.Line,
+		Auth:        authDescription(auth),
+		Middleware:  e.Middleware,
+		BodyType:    e.BodyType,
+	}
+	if doc.Path == "" {
+		doc.Path = strings.TrimSpace(strings.TrimPrefix(it.Name, r.Method))
+	}
+	if doc.Description == "" {
+		doc.Description = e.Doc
+	}
+	if e.SourceFile != "" {
+		doc.Source = sourceText(e.SourceFile, e.Line)
+	}
+
+	for _, v := range r.URL.Variable {
+		doc.PathParams = append(doc.PathParams, Param{Name: v.Key, Value: v.Value, Description: v.Description})
+	}
+	for _, q := range r.URL.Query {
+		doc.Query = append(doc.Query, Param{Name: q.Key, Value: q.Value, Description: q.Description})
+	}
+	for _, h := range r.Header {
+		doc.Headers = append(doc.Headers, Param{Name: h.Key, Value: h.Value, Description: h.Description})
+	}
+	if r.Body != nil {
+		doc.Body = prettyJSON(r.Body.Raw)
+		for _, f := range e.BodyEnums {
+			doc.BodyEnums = append(doc.BodyEnums, Param{Name: f.Field, Value: f.Type, Description: strings.Join(f.Values, ", ")})
+		}
+	}
+	for _, resp := range e.Responses {
+		doc.Responses = append(doc.Responses, Response{
+			Status:      resp.Name(),
+			ContentType: resp.ContentType,
+			Type:        resp.Type,
+			Body:        prettyJSON(resp.Body),
+		})
+	}
+	return doc
+}
+
+// scanned finds the endpoint a collection request was built from: the
+// one with the same method and path whose description (the @route text,
+// or "Source: <file> | ...") matches
+func (b *builder) scanned(name string, r *postman.Request) scan.Endpoint {
+	candidates := b.byName[name]
+	for _, e := range candidates {
+		if e.Desc != "" && e.Desc == r.Description {
+			return e
+		}
+		if e.Desc == "" && strings.HasPrefix(r.Description+" ", "Source: "+e.SourceFile+" ") {
+			return e
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0]
+	}
+	return scan.Endpoint{Method: r.Method}
+}
+
+var nonAnchor = regexp.MustCompile(`[^a-z0-9]+`)
+
+// anchor returns a unique HTML id for an endpoint title
+func (b *builder) anchor(title string) string {
+	id := strings.Trim(nonAnchor.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	base := id
+	for n := 2; b.ids[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	b.ids[id] = true
+	return id
+}
+
+// authDescription describes the auth a request sends
+func authDescription(a *postman.Auth) string {
+	if a == nil {
+		return "None"
+	}
+	m := a.Attrs()
+	switch a.Type {
+	case "bearer":
+		return "Bearer token (" + m["token"] + ")"
+	case "basic":
+		return "Basic auth (" + m["username"] + " / " + m["password"] + ")"
+	case "digest":
+		return "Digest auth (" + m["username"] + " / " + m["password"] + ")"
+	case "apikey":
+		return "API key in the " + m["key"] + " header (" + m["value"] + ")"
+	case "oauth2":
+		return "OAuth 2.0 access token (" + m["accessToken"] + ")"
+	}
+	return "None"
+}
+
+func prettyJSON(s string) string {
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, []byte(s), "", "  ") == nil {
+		return pretty.String()
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package docs
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+var testEndpoints = []scan.Endpoint{
+	{
+		Method: "GET", Path: "/users/{id}", SourceFile: "handlers/users.go", Line: 42,
+		Handler: "getUser", Doc: "getUser returns a single user.",
+		Query: []scan.QueryParam{{Name: "fields", Default: "all"}},
+		Responses: []scan.ResponseExample{
+			{Status: 200, ContentType: "application/json", Body: `{"id":1}`, Type: "models.User"},
+		},
+		Auth: &scan.AuthInfo{Type: "bearer"},
+	},
+	{
+		Method: "POST", Path: "/users", SourceFile: "routes.go", Line: 7, Desc: "Create a user",
+		BodyRaw: `{"name":"string"}`, BodyType: "models.User",
+		BodyEnums: []scan.EnumField{{Field: "role", Type: "models.Role", Values: []string{`"admin"`, `"member"`}}},
+	},
+	{Method: "GET", Path: "/health", SourceFile: "main.go", Line: 3},
+}
+
+func TestBuild(t *testing.T) {
+	ref := Build(postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080", GroupDepth: 1}, testEndpoints)
+	if len(ref.Sections) != 2 || ref.Sections[1].Name != "users" {
+		t.Fatalf("expected health and users sections, got %+v", ref.Sections)
+	}
+	users := ref.Sections[1]
+	if len(users.Endpoints) != 2 {
+		t.Fatalf("expected 2 user endpoints, got %+v", users.Endpoints)
+	}
+	get := users.Endpoints[1]
+	if get.Description != "getUser returns a single user." {
+		t.Errorf("expected handler doc as description, got %q", get.Description)
+	}
+	if get.Source != "handlers/users.go:42" || get.ID != "get-users-id" {
+		t.Errorf("unexpected source/id: %q %q", get.Source, get.ID)
+	}
+	if get.Auth != "Bearer token ({{token}})" {
+		t.Errorf("unexpected auth %q", get.Auth)
+	}
+	if post := users.Endpoints[0]; post.Description != "Create a user" || post.Auth != "None" {
+		t.Errorf("unexpected POST docs: %+v", post)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	md := Build(postman.BuildOpts{Name: "API", GroupDepth: 1}, testEndpoints).Markdown()
+	for _, s := range []string{
+		"# API\n",
+		"\n## users\n",
+		"\n### <a id=\"get-users-id\"></a>`GET /users/{id}`\n\ngetUser returns a single user.\n\n",
+		"**Source:** [handlers/users.go:42](handlers/users.go#L42)",
+		"**Auth:** Bearer token ({{token}})",
+		"#### Path parameters\n\n| Name | Example | Description |\n| --- | --- | --- |\n| `id` | `1` |",
+		"| `fields` | `all` | Detected query parameter |",
+		"#### Request body\n\nType: `models.User`\n\n```json\n{\n  \"name\": \"string\"\n}\n```\n",
+		"#### Allowed values\n\n| Field | Type | Values |\n| --- | --- | --- |\n| `role` | `models.Role` | \"admin\", \"member\" |",
+		"**200 OK** `application/json`, `models.User`\n\n```json\n{\n  \"id\": 1\n}\n```\n",
+	} {
+		if !strings.Contains(md, s) {
+			t.Errorf("missing %q in:\n%s", s, md)
+		}
+	}
+}
+
+func TestHTML(t *testing.T) {
+	page, err := Build(postman.BuildOpts{Name: "API <v1>", GroupDepth: 1}, testEndpoints).HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"<title>API &lt;v1&gt;</title>",
+		`<input id="search" type="search"`,
+		`<div class="endpoint" id="get-users-id">`,
+		`<a href="#get-users-id">`,
+		`Source: <a href="handlers/users.go#L42">handlers/users.go:42</a>`,
+		"<h2 class=\"level-1\">users</h2>",
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("missing %q in page", s)
+		}
+	}
+	if strings.Contains(page, "<link ") || strings.Contains(page, "<script src") {
+		t.Error("page must not load external assets")
+	}
+}
+
+func TestRelativeTo(t *testing.T) {
+	root := t.TempDir()
+	eps := []scan.Endpoint{
+		{Method: "GET", Path: "/users", SourceFile: filepath.Join(root, "handlers", "users.go"), Line: 25},
+		{Method: "GET", Path: "/health", SourceFile: "/elsewhere/main.go", Line: 3},
+	}
+	md := Build(postman.BuildOpts{Name: "API"}, eps).RelativeTo(root).Markdown()
+
+	if !strings.Contains(md, "**Source:** [handlers/users.go:25](handlers/users.go#L25)") {
+		t.Errorf("expected a link relative to the scanned directory in:\n%s", md)
+	}
+	if strings.Contains(md, root) {
+		t.Errorf("scanned directory leaked into the reference:\n%s", md)
+	}
+	if !strings.Contains(md, "(/elsewhere/main.go#L3)") {
+		t.Errorf("expected files outside the directory to be kept in:\n%s", md)
+	}
+}
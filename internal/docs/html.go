@@ -0,0 +1,146 @@
+package docs
+
+import (
+	"html/template"
+	"strings"
+)
+
+// HTML renders the reference as a single self-contained page (inline CSS
+// and JS, no external assets) with a search box filtering the endpoints
+func (r *Reference) HTML() (string, error) {
+	var b strings.Builder
+	if err := pageTemplate.Execute(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+var pageTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"lower":      strings.ToLower,
+	"sourceLink": sourceLink,
+	"params": func(title, value string, params []Param) map[string]interface{} {
+		return map[string]interface{}{"Title": title, "Value": value, "Params": params}
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<style>
+body { margin: 0; font: 15px/1.5 -apple-system, BlinkMacSystemFont, "Segoe UI", Roboto, sans-serif; color: #1f2328; display: flex; }
+nav { width: 300px; height: 100vh; overflow-y: auto; position: sticky; top: 0; border-right: 1px solid #d0d7de; padding: 16px; box-sizing: border-box; background: #f6f8fa; }
+nav input { width: 100%; padding: 6px 8px; box-sizing: border-box; border: 1px solid #d0d7de; border-radius: 6px; margin-bottom: 12px; }
+nav ul { list-style: none; padding-left: 12px; margin: 0; }
+nav > ul { padding-left: 0; }
+nav a { color: inherit; text-decoration: none; display: block; padding: 2px 0; white-space: nowrap; overflow: hidden; text-overflow: ellipsis; }
+nav .folder { font-weight: 600; margin-top: 6px; }
+main { flex: 1; padding: 24px 40px; max-width: 960px; }
+.endpoint { border: 1px solid #d0d7de; border-radius: 8px; padding: 16px 20px; margin: 16px 0; }
+.endpoint h1, .endpoint h2, .endpoint h3, .endpoint h4, .endpoint h5, .endpoint h6 { margin: 0 0 8px; font-size: 18px; }
+.method { display: inline-block; min-width: 56px; text-align: center; border-radius: 4px; color: #fff; font-size: 12px; padding: 1px 6px; margin-right: 6px; background: #6e7781; }
+.method.get { background: #1a7f37; } .method.post { background: #0969da; } .method.put { background: #9a6700; }
+.method.patch { background: #8250df; } .method.delete { background: #cf222e; }
+.meta { color: #57606a; font-size: 13px; }
+table { border-collapse: collapse; margin: 8px 0; }
+th, td { border: 1px solid #d0d7de; padding: 4px 10px; text-align: left; vertical-align: top; }
+pre { background: #f6f8fa; padding: 12px; border-radius: 6px; overflow-x: auto; }
+code { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 13px; }
+section h2 { border-bottom: 1px solid #d0d7de; padding-bottom: 4px; }
+section h2.level-2, section h2.level-3, section h2.level-4 { font-size: 18px; border: 0; }
+.hidden { display: none; }
+</style>
+</head>
+<body>
+<nav>
+<input id="search" type="search" placeholder="Search endpoints..." autocomplete="off">
+<ul>
+{{- range .Endpoints}}{{template "navEndpoint" .}}{{end}}
+{{- range .Sections}}{{template "navSection" .}}{{end}}
+</ul>
+</nav>
+<main>
+<h1>{{.Title}}</h1>
+{{- if .BaseURL}}
+<p class="meta">Base URL: <code>{{.BaseURL}}</code></p>
+{{- end}}
+{{- range .Endpoints}}{{template "endpoint" .}}{{end}}
+{{- range .Sections}}{{template "section" .}}{{end}}
+</main>
+<script>
+(function () {
+  var input = document.getElementById('search');
+  input.addEventListener('input', function () {
+    var q = input.value.trim().toLowerCase();
+    document.querySelectorAll('.endpoint').forEach(function (el) {
+      var match = !q || el.textContent.toLowerCase().indexOf(q) >= 0;
+      el.classList.toggle('hidden', !match);
+      var link = document.querySelector('nav a[href="#' + el.id + '"]');
+      if (link) { link.parentElement.classList.toggle('hidden', !match); }
+    });
+    document.querySelectorAll('section, nav li.folder-item').forEach(function (el) {
+      el.classList.toggle('hidden', !!q && !el.querySelector('.endpoint:not(.hidden), li.endpoint-item:not(.hidden)'));
+    });
+  });
+})();
+</script>
+</body>
+</html>
+{{define "navEndpoint"}}
+<li class="endpoint-item"><a href="#{{.ID}}"><span class="method {{lower .Method}}">{{.Method}}</span>{{.Path}}</a></li>
+{{- end}}
+{{define "navSection"}}
+<li class="folder-item"><span class="folder">{{.Name}}</span><ul>
+{{- range .Endpoints}}{{template "navEndpoint" .}}{{end}}
+{{- range .Sections}}{{template "navSection" .}}{{end}}
+</ul></li>
+{{- end}}
+{{define "section"}}
+<section>
+<h2 class="level-{{.Level}}">{{.Name}}</h2>
+{{- range .Endpoints}}{{template "endpoint" .}}{{end}}
+{{- range .Sections}}{{template "section" .}}{{end}}
+</section>
+{{- end}}
+{{define "params"}}
+{{- if .Params}}
+<h4>{{.Title}}</h4>
+<table><tr><th>Name</th><th>{{.Value}}</th><th>Description</th></tr>
+{{- range .Params}}
+<tr><td><code>{{.Name}}</code></td><td>{{if .Value}}<code>{{.Value}}</code>{{end}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+{{define "endpoint"}}
+<div class="endpoint" id="{{.ID}}">
+<h3><span class="method {{lower .Method}}">{{.Method}}</span><code>{{.Path}}</code></h3>
+{{- if .Description}}
+<p>{{.Description}}</p>
+{{- end}}
+<p class="meta">
+{{- if .Handler}}Handler: <code>{{.Handler}}</code> · {{end}}
+{{- if .Source}}Source: <a href="{{sourceLink .}}">{{.Source}}</a> · {{end}}
+Auth: {{.Auth}}
+{{- if .Middleware}} · Middleware: {{range $i, $m := .Middleware}}{{if $i}}, {{end}}<code>{{$m}}</code>{{end}}{{end}}
+</p>
+{{- template "params" (params "Path parameters" "Example" .PathParams)}}
+{{- template "params" (params "Query parameters" "Default" .Query)}}
+{{- template "params" (params "Headers" "Value" .Headers)}}
+{{- if .Body}}
+<h4>Request body{{if .BodyType}} <code>{{.BodyType}}</code>{{end}}</h4>
+<pre><code>{{.Body}}</code></pre>
+{{- template "params" (params "Allowed values" "Type" .BodyEnums)}}
+{{- end}}
+{{- if .Responses}}
+<h4>Responses</h4>
+{{- range .Responses}}
+<p><strong>{{.Status}}</strong>{{if .ContentType}} <code>{{.ContentType}}</code>{{end}}{{if .Type}} <code>{{.Type}}</code>{{end}}</p>
+{{- if .Body}}
+<pre><code>{{.Body}}</code></pre>
+{{- end}}
+{{- end}}
+{{- end}}
+</div>
+{{- end}}
+`))
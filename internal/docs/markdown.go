@@ -0,0 +1,132 @@
+package docs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders the reference as a Markdown document. Folders become
+// headings below the title; endpoints are one level deeper.
+func (r *Reference) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", r.Title)
+	if r.BaseURL != "" {
+		fmt.Fprintf(&b, "Base URL: `%s`\n", r.BaseURL)
+	}
+	for _, e := range r.Endpoints {
+		writeMarkdownEndpoint(&b, e, 2)
+	}
+	for _, s := range r.Sections {
+		writeMarkdownSection(&b, s)
+	}
+	return b.String()
+}
+
+func writeMarkdownSection(b *strings.Builder, s Section) {
+	fmt.Fprintf(b, "\n%s %s\n", heading(s.Level+1), s.Name)
+	for _, e := range s.Endpoints {
+		writeMarkdownEndpoint(b, e, s.Level+2)
+	}
+	for _, sub := range s.Sections {
+		writeMarkdownSection(b, sub)
+	}
+}
+
+func writeMarkdownEndpoint(b *strings.Builder, e Endpoint, level int) {
+	fmt.Fprintf(b, "\n%s <a id=\"%s\"></a>`%s`\n\n", heading(level), e.ID, e.Title)
+	if e.Description != "" {
+		b.WriteString(e.Description + "\n\n")
+	}
+
+	var meta []string
+	if e.Handler != "" {
+		meta = append(meta, "**Handler:** `"+e.Handler+"`")
+	}
+	if e.Source != "" {
+		meta = append(meta, "**Source:** ["+e.Source+"]("+sourceLink(e)+")")
+	}
+	meta = append(meta, "**Auth:** "+e.Auth)
+	if len(e.Middleware) > 0 {
+		meta = append(meta, "**Middleware:** `"+strings.Join(e.Middleware, "`, `")+"`")
+	}
+	b.WriteString(strings.Join(meta, "  \n") + "\n")
+
+	sub := heading(level + 1)
+	writeMarkdownParams(b, sub+" Path parameters", []string{"Name", "Example", "Description"}, e.PathParams)
+	writeMarkdownParams(b, sub+" Query parameters", []string{"Name", "Default", "Description"}, e.Query)
+	writeMarkdownParams(b, sub+" Headers", []string{"Name", "Value", "Description"}, e.Headers)
+
+	if e.Body != "" {
+		fmt.Fprintf(b, "\n%s Request body\n\n", sub)
+		if e.BodyType != "" {
+			fmt.Fprintf(b, "Type: `%s`\n\n", e.BodyType)
+		}
+		b.WriteString(codeBlock(e.Body))
+		writeMarkdownParams(b, sub+" Allowed values", []string{"Field", "Type", "Values"}, e.BodyEnums)
+	}
+
+	if len(e.Responses) > 0 {
+		fmt.Fprintf(b, "\n%s Responses\n", sub)
+		for _, r := range e.Responses {
+			details := []string{}
+			if r.ContentType != "" {
+				details = append(details, "`"+r.ContentType+"`")
+			}
+			if r.Type != "" {
+				details = append(details, "`"+r.Type+"`")
+			}
+			fmt.Fprintf(b, "\n**%s**", r.Status)
+			if len(details) > 0 {
+				b.WriteString(" " + strings.Join(details, ", "))
+			}
+			b.WriteString("\n")
+			if r.Body != "" {
+				b.WriteString("\n" + codeBlock(r.Body))
+			}
+		}
+	}
+}
+
+func writeMarkdownParams(b *strings.Builder, title string, columns []string, params []Param) {
+	if len(params) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%s\n\n", title)
+	b.WriteString("| " + strings.Join(columns, " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, p := range params {
+		value := ""
+		if p.Value != "" {
+			value = "`" + cell(p.Value) + "`"
+		}
+		fmt.Fprintf(b, "| `%s` | %s | %s |\n", p.Name, value, cell(p.Description))
+	}
+}
+
+// cell escapes text for a Markdown table cell
+func cell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+func codeBlock(body string) string {
+	lang := ""
+	if strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[") {
+		lang = "json"
+	}
+	return "```" + lang + "\n" + strings.TrimRight(body, "\n") + "\n```\n"
+}
+
+func heading(level int) string {
+	if level > 6 {
+		level = 6
+	}
+	return strings.Repeat("#", level)
+}
+
+// sourceLink links to the line the way GitHub and GitLab render it
+func sourceLink(e Endpoint) string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s#L%d", e.SourceFile, e.Line)
+	}
+	return e.SourceFile
+}
@@ -0,0 +1,256 @@
+// Package har exports scanned endpoints as a HAR 1.2 log with one entry
+// per endpoint. Requests are fully materialized: {{variables}} are
+// substituted from an environment and path variables get their examples.
+package har
+
+import (
+	"encoding/base64"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Time            float64  `json:"time"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	Cache           struct{} `json:"cache"`
+	Timings         Timings  `json:"timings"`
+	Comment         string   `json:"comment,omitempty"`
+}
+
+type Request struct {
+	Method      string    `json:"method"`
+	URL         string    `json:"url"`
+	HTTPVersion string    `json:"httpVersion"`
+	Cookies     []NVP     `json:"cookies"`
+	Headers     []NVP     `json:"headers"`
+	QueryString []NVP     `json:"queryString"`
+	PostData    *PostData `json:"postData,omitempty"`
+	HeadersSize int       `json:"headersSize"`
+	BodySize    int       `json:"bodySize"`
+}
+
+type Response struct {
+	Status      int     `json:"status"`
+	StatusText  string  `json:"statusText"`
+	HTTPVersion string  `json:"httpVersion"`
+	Cookies     []NVP   `json:"cookies"`
+	Headers     []NVP   `json:"headers"`
+	Content     Content `json:"content"`
+	RedirectURL string  `json:"redirectURL"`
+	HeadersSize int     `json:"headersSize"`
+	BodySize    int     `json:"bodySize"`
+}
+
+// NVP is a name/value pair (header, cookie or query parameter)
+type NVP struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type PostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type Content struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+}
+
+type Timings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// Build returns one entry per endpoint, in the order of the Postman tree
+// built with the same options. Variables are replaced by the enabled
+// values of env; unknown ones are kept as {{name}}.
+func Build(opts postman.BuildOpts, env postman.Environment, eps []scan.Endpoint) HAR {
+	col := postman.BuildCollection(opts, eps)
+
+	values := make(map[string]string)
+	for _, v := range env.Values {
+		if v.Enabled {
+			values[v.Key] = v.Value
+		}
+	}
+	b := &builder{
+		values:  values,
+		started: time.Now().UTC().Format(time.RFC3339),
+	}
+	postman.Walk(col.Item, col.Auth, func(it postman.Item, auth *postman.Auth) {
+		b.entries = append(b.entries, Entry{
+			StartedDateTime: b.started,
+			Request:         b.request(it.Request, auth),
+			Response:        response(it.Response),
+			Comment:         it.Name,
+		})
+	})
+
+	return HAR{Log: Log{
+		Version: "1.2",
+		Creator: Creator{Name: "postman-gen", Version: "1.0.0"},
+		Entries: b.entries,
+	}}
+}
+
+type builder struct {
+	values  map[string]string
+	started string
+	entries []Entry
+}
+
+func (b *builder) request(r *postman.Request, auth *postman.Auth) Request {
+	// Routes registered for any method are sent as GET
+	method := strings.ToUpper(r.Method)
+	if method == "ANY" || method == "" {
+		method = http.MethodGet
+	}
+
+	req := Request{
+		Method:      method,
+		URL:         b.substitute(r.URL.Expand()),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []NVP{},
+		Headers:     []NVP{},
+		QueryString: []NVP{},
+		HeadersSize: -1,
+		BodySize:    0,
+	}
+
+	if h := b.authHeader(auth); h != nil {
+		req.Headers = append(req.Headers, *h)
+	}
+	// The collection keeps annotation headers in map order
+	headers := append([]postman.Header(nil), r.Header...)
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Key < headers[j].Key })
+	for _, h := range headers {
+		value := b.substitute(h.Value)
+		req.Headers = append(req.Headers, NVP{Name: h.Key, Value: value})
+		if strings.EqualFold(h.Key, "Cookie") {
+			req.Cookies = append(req.Cookies, parseCookies(value)...)
+		}
+	}
+
+	if r.Body != nil && r.Body.Raw != "" {
+		mimeType := "application/json"
+		for _, h := range r.Header {
+			if strings.EqualFold(h.Key, "Content-Type") {
+				mimeType = h.Value
+			}
+		}
+		text := b.substitute(r.Body.Raw)
+		req.PostData = &PostData{MimeType: mimeType, Text: text}
+		req.BodySize = len(text)
+	}
+	return req
+}
+
+// authHeader materializes auth as a header; digest auth needs a server
+// challenge and is left out
+func (b *builder) authHeader(a *postman.Auth) *NVP {
+	if a == nil {
+		return nil
+	}
+	m := a.Attrs()
+	for k, v := range m {
+		m[k] = b.substitute(v)
+	}
+	switch a.Type {
+	case "bearer":
+		return &NVP{Name: "Authorization", Value: "Bearer " + m["token"]}
+	case "oauth2":
+		return &NVP{Name: "Authorization", Value: "Bearer " + m["accessToken"]}
+	case "basic":
+		creds := base64.StdEncoding.EncodeToString([]byte(m["username"] + ":" + m["password"]))
+		return &NVP{Name: "Authorization", Value: "Basic " + creds}
+	case "apikey":
+		return &NVP{Name: m["key"], Value: m["value"]}
+	}
+	return nil
+}
+
+// response fills the entry's response from the first successful example
+// (or the first example at all); without examples it stays empty, with
+// status 0 as browsers record requests that got no response
+func response(examples []postman.Response) Response {
+	resp := Response{
+		Cookies:     []NVP{},
+		Headers:     []NVP{},
+		HeadersSize: -1,
+		BodySize:    -1,
+	}
+	if len(examples) == 0 {
+		return resp
+	}
+	ex := examples[0]
+	for _, e := range examples {
+		if e.Code >= 200 && e.Code < 300 {
+			ex = e
+			break
+		}
+	}
+	resp.Status = ex.Code
+	resp.StatusText = ex.Status
+	resp.HTTPVersion = "HTTP/1.1"
+	for _, h := range ex.Header {
+		resp.Headers = append(resp.Headers, NVP{Name: h.Key, Value: h.Value})
+		if strings.EqualFold(h.Key, "Content-Type") {
+			resp.Content.MimeType = h.Value
+		}
+	}
+	resp.Content.Text = ex.Body
+	resp.Content.Size = len(ex.Body)
+	resp.BodySize = len(ex.Body)
+	return resp
+}
+
+var (
+	variableRe = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+)
+
+// substitute replaces {{variables}} known to the environment
+func (b *builder) substitute(s string) string {
+	return variableRe.ReplaceAllStringFunc(s, func(ref string) string {
+		if v, ok := b.values[variableRe.FindStringSubmatch(ref)[1]]; ok {
+			return v
+		}
+		return ref
+	})
+}
+
+func parseCookies(header string) []NVP {
+	var cookies []NVP
+	for _, part := range strings.Split(header, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && name != "" {
+			cookies = append(cookies, NVP{Name: name, Value: value})
+		}
+	}
+	return cookies
+}
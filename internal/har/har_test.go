@@ -0,0 +1,67 @@
+package har
+
+import (
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func TestBuild(t *testing.T) {
+	eps := []scan.Endpoint{
+		{
+			Method: "GET", Path: "/v1/users/{id}",
+			RequestHeaders: []scan.HeaderParam{{Name: "X-Tenant-ID"}},
+			Responses: []scan.ResponseExample{
+				{Status: 404, ContentType: "application/json", Body: `{"error":"not found"}`},
+				{Status: 200, ContentType: "application/json", Body: `{"id":1}`},
+			},
+		},
+		{Method: "POST", Path: "/v1/users", BodyRaw: `{"name":"Ada"}`, Auth: &scan.AuthInfo{Type: "basic"}},
+	}
+	opts := postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080"}
+	env := postman.BuildEnvironment("Local", "http://localhost:8080", "username", "password")
+	env.Values[1].Value = "ada"
+	env.Values[2].Value = "secret"
+
+	log := Build(opts, env, eps).Log
+	if log.Version != "1.2" || len(log.Entries) != 2 {
+		t.Fatalf("version %q, %d entries", log.Version, len(log.Entries))
+	}
+
+	post := log.Entries[0]
+	if post.Request.Method != "POST" || post.Request.URL != "http://localhost:8080/v1/users" {
+		t.Errorf("request = %s %s", post.Request.Method, post.Request.URL)
+	}
+	if len(post.Request.Headers) == 0 || post.Request.Headers[0] != (NVP{Name: "Authorization", Value: "Basic YWRhOnNlY3JldA=="}) {
+		t.Errorf("headers = %+v", post.Request.Headers)
+	}
+	if post.Request.PostData == nil || post.Request.PostData.Text != `{"name":"Ada"}` || post.Request.BodySize != 14 {
+		t.Errorf("postData = %+v, bodySize %d", post.Request.PostData, post.Request.BodySize)
+	}
+	if post.Response.Status != 0 || post.Response.Content.Text != "" || post.Response.Headers == nil {
+		t.Errorf("response without examples = %+v", post.Response)
+	}
+
+	get := log.Entries[1]
+	if get.Request.URL != "http://localhost:8080/v1/users/1" {
+		t.Errorf("url = %q", get.Request.URL)
+	}
+	if get.Request.PostData != nil {
+		t.Errorf("GET has postData %+v", get.Request.PostData)
+	}
+	if get.Request.Headers[0].Name != "X-Tenant-ID" {
+		t.Errorf("headers = %+v", get.Request.Headers)
+	}
+	resp := get.Response
+	if resp.Status != 200 || resp.StatusText != "OK" || resp.Content.MimeType != "application/json" || resp.Content.Text == "" {
+		t.Errorf("response = %+v", resp)
+	}
+}
+
+func TestParseCookies(t *testing.T) {
+	got := parseCookies("session=abc; theme=dark;")
+	if len(got) != 2 || got[0] != (NVP{Name: "session", Value: "abc"}) || got[1] != (NVP{Name: "theme", Value: "dark"}) {
+		t.Errorf("parseCookies = %+v", got)
+	}
+}
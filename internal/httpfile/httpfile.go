@@ -0,0 +1,157 @@
+// Package httpfile renders scanned endpoints as .http files for the
+// JetBrains HTTP Client and the VS Code REST Client, plus the
+// http-client.env.json environment file both of them can read.
+package httpfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// File is a rendered .http file, named relative to the output directory
+type File struct {
+	Path    string
+	Content string
+}
+
+// Build renders eps in the order of the Postman tree built with the same
+// options. With split, every top-level folder gets its own file and
+// requests outside folders go to a file named after the collection;
+// otherwise everything is written to a single file.
+func Build(opts postman.BuildOpts, split bool, eps []scan.Endpoint) []File {
+	col := postman.BuildCollection(opts, eps)
+	base := fileName(opts.Name)
+
+	if !split {
+		var b strings.Builder
+		writeItems(&b, col.Item, col.Auth)
+		return []File{{Path: base + ".http", Content: b.String()}}
+	}
+
+	var files []File
+	var loose strings.Builder
+	used := map[string]bool{base: true}
+	for _, it := range col.Item {
+		if it.Request != nil {
+			writeItems(&loose, []postman.Item{it}, col.Auth)
+			continue
+		}
+		var b strings.Builder
+		writeItems(&b, it.Item, it.ResolveAuth(col.Auth))
+		name := fileName(it.Name)
+		for n := 2; used[name]; n++ {
+			name = fmt.Sprintf("%s-%d", fileName(it.Name), n)
+		}
+		used[name] = true
+		files = append(files, File{Path: name + ".http", Content: b.String()})
+	}
+	if loose.Len() > 0 {
+		files = append([]File{{Path: base + ".http", Content: loose.String()}}, files...)
+	}
+	return files
+}
+
+// Environment renders env as http-client.env.json, keyed by its name.
+// Credential variables are included with empty values to be filled in
+// (or moved to http-client.private.env.json).
+func Environment(env postman.Environment) ([]byte, error) {
+	vars := make(map[string]string, len(env.Values))
+	for _, v := range env.Values {
+		vars[v.Key] = v.Value
+	}
+	return json.MarshalIndent(map[string]map[string]string{env.Name: vars}, "", "  ")
+}
+
+// writeItems writes every request below items, resolving the auth each
+// request inherits from its folders
+func writeItems(b *strings.Builder, items []postman.Item, inherited *postman.Auth) {
+	postman.Walk(items, inherited, func(it postman.Item, auth *postman.Auth) {
+		writeRequest(b, it.Name, it.Request, auth)
+	})
+}
+
+func writeRequest(b *strings.Builder, name string, r *postman.Request, auth *postman.Auth) {
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("### " + name + "\n")
+	if r.Description != "" {
+		for _, line := range strings.Split(r.Description, "\n") {
+			b.WriteString("# " + line + "\n")
+		}
+	}
+	if len(r.URL.Query) > 0 {
+		var names []string
+		for _, q := range r.URL.Query {
+			names = append(names, q.Key)
+		}
+		b.WriteString("# Query parameters: " + strings.Join(names, ", ") + "\n")
+	}
+
+	// Routes registered for any method are sent as GET
+	method := strings.ToUpper(r.Method)
+	if method == "ANY" || method == "" {
+		method = http.MethodGet
+	}
+	b.WriteString(method + " " + r.URL.Expand() + "\n")
+
+	// The collection keeps annotation headers in map order
+	headers := append([]postman.Header(nil), r.Header...)
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Key < headers[j].Key })
+	if h := authHeader(auth); h != nil {
+		headers = append([]postman.Header{*h}, headers...)
+	}
+	for _, h := range headers {
+		b.WriteString(h.Key + ": " + h.Value + "\n")
+	}
+
+	if r.Body != nil && r.Body.Raw != "" {
+		body := r.Body.Raw
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, []byte(body), "", "  ") == nil {
+			body = pretty.String()
+		}
+		b.WriteString("\n" + body + "\n")
+	}
+}
+
+// authHeader turns auth into the Authorization (or API key) header; both
+// clients expand "Basic user pass" and "Digest user pass" themselves
+func authHeader(a *postman.Auth) *postman.Header {
+	if a == nil {
+		return nil
+	}
+	m := a.Attrs()
+	switch a.Type {
+	case "bearer":
+		return &postman.Header{Key: "Authorization", Value: "Bearer " + m["token"]}
+	case "oauth2":
+		return &postman.Header{Key: "Authorization", Value: "Bearer " + m["accessToken"]}
+	case "basic":
+		return &postman.Header{Key: "Authorization", Value: "Basic " + m["username"] + " " + m["password"]}
+	case "digest":
+		return &postman.Header{Key: "Authorization", Value: "Digest " + m["username"] + " " + m["password"]}
+	case "apikey":
+		return &postman.Header{Key: m["key"], Value: m["value"]}
+	}
+	return nil
+}
+
+var unsafeName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// fileName turns a folder or collection name into a portable file name
+func fileName(name string) string {
+	s := strings.Trim(unsafeName.ReplaceAllString(name, "-"), "-.")
+	if s == "" {
+		return "requests"
+	}
+	return s
+}
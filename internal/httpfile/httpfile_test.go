@@ -0,0 +1,61 @@
+package httpfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+var testEndpoints = []scan.Endpoint{
+	{Method: "GET", Path: "/users/{id}", Query: []scan.QueryParam{{Name: "fields"}}},
+	{Method: "POST", Path: "/users", BodyRaw: `{"name":"string"}`, Auth: &scan.AuthInfo{Type: "bearer"}},
+	{Method: "GET", Path: "/health"},
+}
+
+func TestBuild(t *testing.T) {
+	files := Build(postman.BuildOpts{Name: "Users API", GroupDepth: 1}, false, testEndpoints)
+	if len(files) != 1 || files[0].Path != "Users-API.http" {
+		t.Fatalf("expected a single Users-API.http, got %+v", files)
+	}
+	content := files[0].Content
+
+	for _, s := range []string{
+		"### GET /health\n",
+		"### GET /users/{id}\n# Source: \n# Query parameters: fields\nGET {{baseUrl}}/users/1\n",
+		"### POST /users\n# Source: \nPOST {{baseUrl}}/users\nAuthorization: Bearer {{token}}\nContent-Type: application/json\n\n{\n  \"name\": \"string\"\n}\n",
+	} {
+		if !strings.Contains(content, s) {
+			t.Errorf("missing %q in:\n%s", s, content)
+		}
+	}
+	if strings.Index(content, "### GET /health") > strings.Index(content, "### POST /users") {
+		t.Errorf("expected requests in collection order:\n%s", content)
+	}
+}
+
+func TestBuild_Split(t *testing.T) {
+	files := Build(postman.BuildOpts{Name: "API", GroupDepth: 1}, true, testEndpoints)
+	var paths []string
+	for _, f := range files {
+		paths = append(paths, f.Path)
+	}
+	if strings.Join(paths, ",") != "health.http,users.http" {
+		t.Fatalf("expected one file per top-level folder, got %v", paths)
+	}
+	if strings.Count(files[1].Content, "###") != 2 {
+		t.Errorf("expected both user requests in users.http:\n%s", files[1].Content)
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	data, err := Environment(postman.BuildEnvironment("dev", "http://localhost:8080", "token"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"dev\": {\n    \"baseUrl\": \"http://localhost:8080\",\n    \"token\": \"\"\n  }\n}"
+	if string(data) != want {
+		t.Errorf("unexpected environment:\n%s", data)
+	}
+}
@@ -0,0 +1,180 @@
+// Package hurl renders scanned endpoints as a Hurl file, so a freshly
+// scanned service can be smoke-tested with
+// `hurl --test --variable baseUrl=... api.hurl`.
+package hurl
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// Build renders one Hurl entry per endpoint, in the order of the Postman
+// tree built with the same options. Variables ({{baseUrl}} and the auth
+// credentials) are left for --variable or --variables-file.
+func Build(opts postman.BuildOpts, eps []scan.Endpoint) string {
+	col := postman.BuildCollection(opts, eps)
+
+	var entries strings.Builder
+	postman.Walk(col.Item, col.Auth, func(it postman.Item, auth *postman.Auth) {
+		writeEntry(&entries, it, auth)
+	})
+
+	// Hurl fails on undefined variables, so the usage line lists them all
+	var b strings.Builder
+	b.WriteString("# Generated by postman-gen. Run with:\n")
+	b.WriteString("#   hurl --test --variable baseUrl=" + opts.BaseURL)
+	for _, v := range variables(entries.String()) {
+		if v != "baseUrl" {
+			b.WriteString(" --variable " + v + "=...")
+		}
+	}
+	b.WriteString(" <file>\n")
+	b.WriteString(entries.String())
+	return b.String()
+}
+
+var variableRe = regexp.MustCompile(`\{\{([A-Za-z0-9_.-]+)\}\}`)
+
+// variables lists the {{variables}} referenced in s, sorted
+func variables(s string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, m := range variableRe.FindAllStringSubmatch(s, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Variables renders env as a Hurl --variables-file
+func Variables(env postman.Environment) string {
+	var b strings.Builder
+	for _, v := range env.Values {
+		b.WriteString(v.Key + "=" + v.Value + "\n")
+	}
+	return b.String()
+}
+
+func writeEntry(b *strings.Builder, it postman.Item, auth *postman.Auth) {
+	r := it.Request
+	b.WriteString("\n# " + it.Name + "\n")
+
+	// Routes registered for any method are sent as GET
+	method := strings.ToUpper(r.Method)
+	if method == "ANY" || method == "" {
+		method = http.MethodGet
+	}
+	b.WriteString(method + " " + r.URL.Expand() + "\n")
+
+	// The collection keeps annotation headers in map order
+	headers := append([]postman.Header(nil), r.Header...)
+	sort.SliceStable(headers, func(i, j int) bool { return headers[i].Key < headers[j].Key })
+	basic := ""
+	if auth != nil {
+		m := auth.Attrs()
+		switch auth.Type {
+		case "bearer":
+			headers = append([]postman.Header{{Key: "Authorization", Value: "Bearer " + m["token"]}}, headers...)
+		case "oauth2":
+			headers = append([]postman.Header{{Key: "Authorization", Value: "Bearer " + m["accessToken"]}}, headers...)
+		case "apikey":
+			headers = append([]postman.Header{{Key: m["key"], Value: m["value"]}}, headers...)
+		case "basic":
+			basic = m["username"] + ": " + m["password"]
+		}
+	}
+	for _, h := range headers {
+		b.WriteString(h.Key + ": " + h.Value + "\n")
+	}
+	if basic != "" {
+		b.WriteString("[BasicAuth]\n" + basic + "\n")
+	}
+
+	if r.Body != nil && r.Body.Raw != "" {
+		body := r.Body.Raw
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, []byte(body), "", "  ") == nil {
+			body = pretty.String()
+		}
+		b.WriteString(body + "\n")
+	}
+
+	if resp := expectedResponse(it.Response); resp != nil {
+		writeAsserts(b, resp)
+	}
+}
+
+// expectedResponse picks the first successful response detected for the
+// request; error responses and unresolved status codes are not asserted
+func expectedResponse(responses []postman.Response) *postman.Response {
+	for i := range responses {
+		if responses[i].Code >= 200 && responses[i].Code < 300 {
+			return &responses[i]
+		}
+	}
+	return nil
+}
+
+func writeAsserts(b *strings.Builder, resp *postman.Response) {
+	b.WriteString("\nHTTP " + strconv.Itoa(resp.Code) + "\n")
+	if resp.PreviewLanguage != "json" || resp.Body == "" {
+		return
+	}
+	dec := json.NewDecoder(strings.NewReader(resp.Body))
+	tok, err := dec.Token()
+	if err != nil {
+		return
+	}
+	var asserts []string
+	switch tok {
+	case json.Delim('['):
+		asserts = append(asserts, `jsonpath "$" isCollection`)
+	case json.Delim('{'):
+		for _, key := range objectKeys(dec) {
+			asserts = append(asserts, "jsonpath "+jsonPath(key)+" exists")
+		}
+	}
+	if len(asserts) > 0 {
+		b.WriteString("[Asserts]\n" + strings.Join(asserts, "\n") + "\n")
+	}
+}
+
+// objectKeys returns the keys of the object whose opening brace was just
+// read from dec, in document order
+func objectKeys(dec *json.Decoder) []string {
+	var keys []string
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return keys
+		}
+		key, _ := tok.(string)
+		keys = append(keys, key)
+		var skip json.RawMessage
+		if dec.Decode(&skip) != nil {
+			return keys
+		}
+	}
+	return keys
+}
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPath quotes the key unless it is a plain identifier
+func jsonPath(key string) string {
+	if identifier.MatchString(key) {
+		return `"$.` + key + `"`
+	}
+	return `"$['` + strings.ReplaceAll(key, `'`, `\'`) + `']"`
+}
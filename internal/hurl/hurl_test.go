@@ -0,0 +1,57 @@
+package hurl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func TestBuild(t *testing.T) {
+	eps := []scan.Endpoint{
+		{
+			Method: "GET", Path: "/users/{id}",
+			RequestHeaders: []scan.HeaderParam{{Name: "X-Tenant-ID"}},
+			Responses: []scan.ResponseExample{
+				{Status: 404, ContentType: "text/plain", Body: "not found"},
+				{Status: 200, ContentType: "application/json", Body: `{"id":0,"name":"string","first-name":"string"}`},
+			},
+		},
+		{
+			Method: "POST", Path: "/users", BodyRaw: `{"name":"string"}`,
+			Auth:      &scan.AuthInfo{Type: "basic"},
+			Responses: []scan.ResponseExample{{Status: 201, ContentType: "application/json", Body: `[]`}},
+		},
+		{Method: "DELETE", Path: "/users/{id}"},
+	}
+	got := Build(postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080"}, eps)
+
+	for _, s := range []string{
+		"#   hurl --test --variable baseUrl=http://localhost:8080 --variable password=... --variable tenantId=... --variable username=... <file>\n",
+		"# GET /users/{id}\nGET {{baseUrl}}/users/1\nX-Tenant-ID: {{tenantId}}\n\nHTTP 200\n[Asserts]\n" +
+			"jsonpath \"$.id\" exists\njsonpath \"$.name\" exists\njsonpath \"$['first-name']\" exists\n",
+		"# POST /users\nPOST {{baseUrl}}/users\nContent-Type: application/json\n[BasicAuth]\n{{username}}: {{password}}\n" +
+			"{\n  \"name\": \"string\"\n}\n\nHTTP 201\n[Asserts]\njsonpath \"$\" isCollection\n",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q in:\n%s", s, got)
+		}
+	}
+
+	// Without a known successful response there is nothing to assert
+	del := got[strings.Index(got, "# DELETE"):]
+	if end := strings.Index(del[1:], "\n# "); end >= 0 {
+		del = del[:end+1]
+	}
+	if strings.Contains(del, "HTTP ") {
+		t.Errorf("expected no assertions for DELETE:\n%s", del)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	got := Variables(postman.BuildEnvironment("Local", "http://localhost:8080", "token"))
+	if got != "baseUrl=http://localhost:8080\ntoken=\n" {
+		t.Errorf("unexpected variables file:\n%s", got)
+	}
+}
@@ -0,0 +1,210 @@
+// Package insomnia converts scanned endpoints into an Insomnia v4 export.
+// The folder tree is taken from the Postman collection built with the same
+// options, so both formats group requests identically.
+package insomnia
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+const exportFormat = 4
+
+type Export struct {
+	Type         string     `json:"_type"`
+	ExportFormat int        `json:"__export_format"`
+	ExportDate   string     `json:"__export_date"`
+	ExportSource string     `json:"__export_source"`
+	Resources    []Resource `json:"resources"`
+}
+
+// Resource is any exported object: workspace, environment, request_group
+// or request. Fields not used by a type are left empty.
+type Resource struct {
+	ID             string                 `json:"_id"`
+	Type           string                 `json:"_type"`
+	ParentID       *string                `json:"parentId"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description,omitempty"`
+	Scope          string                 `json:"scope,omitempty"`
+	Data           map[string]string      `json:"data,omitempty"`
+	Environment    map[string]string      `json:"environment,omitempty"`
+	Method         string                 `json:"method,omitempty"`
+	URL            string                 `json:"url,omitempty"`
+	Body           *Body                  `json:"body,omitempty"`
+	Headers        []Pair                 `json:"headers,omitempty"`
+	Parameters     []Pair                 `json:"parameters,omitempty"`
+	PathParameters []Pair                 `json:"pathParameters,omitempty"`
+	Authentication map[string]interface{} `json:"authentication,omitempty"`
+	MetaSortKey    int                    `json:"metaSortKey,omitempty"`
+}
+
+type Body struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// Pair is a header, query parameter or path parameter
+type Pair struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// Build groups eps like postman.BuildCollection does with the same options
+// and returns them as an Insomnia export with a base environment holding
+// baseUrl and the auth credentials
+func Build(opts postman.BuildOpts, eps []scan.Endpoint) Export {
+	col := postman.BuildCollection(opts, eps)
+
+	workspaceID := resourceID("wrk", opts.Name)
+	b := &builder{resources: []Resource{{
+		ID:          workspaceID,
+		Type:        "workspace",
+		ParentID:    nil,
+		Name:        opts.Name,
+		Description: col.Info.Description,
+		Scope:       "collection",
+	}}}
+
+	data := make(map[string]string)
+	for _, v := range postman.CollectionEnvironment("", opts.BaseURL, col).Values {
+		data[v.Key] = v.Value
+	}
+	b.resources = append(b.resources, Resource{
+		ID:       resourceID("env", workspaceID),
+		Type:     "environment",
+		ParentID: &workspaceID,
+		Name:     "Base Environment",
+		Data:     data,
+	})
+
+	b.items(workspaceID, col.Item, col.Auth)
+
+	return Export{
+		Type:         "export",
+		ExportFormat: exportFormat,
+		ExportDate:   time.Now().Format(time.RFC3339),
+		ExportSource: "postman-gen",
+		Resources:    b.resources,
+	}
+}
+
+type builder struct {
+	resources []Resource
+}
+
+// items appends folders and requests under parentID. Insomnia only sees
+// the auth of the request itself, so the auth a Postman request would
+// inherit from its folders is resolved here.
+func (b *builder) items(parentID string, items []postman.Item, inherited *postman.Auth) {
+	for i, it := range items {
+		id := resourceID(kindPrefix(it), parentID+"/"+strconv.Itoa(i)+"/"+it.Name)
+		parent := parentID
+		if it.Request == nil {
+			b.resources = append(b.resources, Resource{
+				ID:          id,
+				Type:        "request_group",
+				ParentID:    &parent,
+				Name:        it.Name,
+				Environment: map[string]string{},
+				MetaSortKey: -(len(items) - i),
+			})
+			b.items(id, it.Item, it.ResolveAuth(inherited))
+			continue
+		}
+
+		req := requestResource(it.Name, it.Request, it.ResolveAuth(inherited))
+		req.ID = id
+		req.ParentID = &parent
+		req.MetaSortKey = -(len(items) - i)
+		b.resources = append(b.resources, req)
+	}
+}
+
+func requestResource(name string, r *postman.Request, auth *postman.Auth) Resource {
+	res := Resource{
+		Type:           "request",
+		Name:           name,
+		Description:    r.Description,
+		Method:         r.Method,
+		URL:            template(strings.SplitN(r.URL.Raw, "?", 2)[0]),
+		Authentication: authentication(auth),
+	}
+
+	mimeType := "application/json"
+	for _, h := range r.Header {
+		if strings.EqualFold(h.Key, "Content-Type") {
+			mimeType = h.Value
+		}
+		res.Headers = append(res.Headers, Pair{Name: h.Key, Value: template(h.Value), Description: h.Description})
+	}
+	if r.Body != nil && r.Body.Raw != "" {
+		res.Body = &Body{MimeType: mimeType, Text: r.Body.Raw}
+	}
+	for _, q := range r.URL.Query {
+		res.Parameters = append(res.Parameters, Pair{
+			Name:        q.Key,
+			Value:       q.Value,
+			Description: q.Description,
+			Disabled:    q.Disabled,
+		})
+	}
+	for _, v := range r.URL.Variable {
+		res.PathParameters = append(res.PathParameters, Pair{Name: v.Key, Value: v.Value})
+	}
+	return res
+}
+
+// authentication maps Postman auth to Insomnia's per-request auth; a nil
+// or noauth auth yields an empty object (no authentication)
+func authentication(a *postman.Auth) map[string]interface{} {
+	if a == nil {
+		return map[string]interface{}{}
+	}
+	m := a.Attrs()
+	for k, v := range m {
+		m[k] = template(v)
+	}
+	switch a.Type {
+	case "bearer":
+		return map[string]interface{}{"type": "bearer", "token": m["token"]}
+	case "basic", "digest":
+		return map[string]interface{}{"type": a.Type, "username": m["username"], "password": m["password"]}
+	case "apikey":
+		return map[string]interface{}{"type": "apikey", "key": m["key"], "value": m["value"], "addTo": "header"}
+	case "oauth2":
+		// The access token is supplied directly, so it is sent as a bearer
+		return map[string]interface{}{"type": "bearer", "token": m["accessToken"]}
+	}
+	return map[string]interface{}{}
+}
+
+var variableRe = regexp.MustCompile(`\{\{\s*([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// template rewrites Postman {{var}} references as Insomnia's {{ _.var }}
+func template(s string) string {
+	return variableRe.ReplaceAllString(s, "{{ _.$1 }}")
+}
+
+func kindPrefix(it postman.Item) string {
+	if it.Request == nil {
+		return "fld"
+	}
+	return "req"
+}
+
+// resourceID derives a stable id from seed, so re-exports of the same
+// tree produce the same ids and Insomnia updates instead of duplicating
+func resourceID(prefix, seed string) string {
+	sum := sha1.Sum([]byte(prefix + ":" + seed))
+	return prefix + "_" + hex.EncodeToString(sum[:])[:32]
+}
@@ -0,0 +1,79 @@
+package insomnia
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func TestBuild(t *testing.T) {
+	eps := []scan.Endpoint{
+		{Method: "GET", Path: "/users/{id}", Query: []scan.QueryParam{{Name: "fields"}}},
+		{Method: "POST", Path: "/users", BodyRaw: `{"name":"string"}`, Auth: &scan.AuthInfo{Type: "bearer"}},
+		{Method: "GET", Path: "/health"},
+	}
+	exp := Build(postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080", GroupDepth: 1}, eps)
+
+	if exp.Type != "export" || exp.ExportFormat != 4 {
+		t.Fatalf("unexpected export header: %s %d", exp.Type, exp.ExportFormat)
+	}
+
+	byName := make(map[string]Resource)
+	byID := make(map[string]Resource)
+	for _, r := range exp.Resources {
+		byName[r.Type+" "+r.Name] = r
+		byID[r.ID] = r
+	}
+
+	ws, ok := byName["workspace API"]
+	if !ok || ws.ParentID != nil {
+		t.Fatalf("expected root workspace, got %+v", exp.Resources)
+	}
+	env := byName["environment Base Environment"]
+	if env.Data["baseUrl"] != "http://localhost:8080" {
+		t.Errorf("expected baseUrl in base environment, got %v", env.Data)
+	}
+	if _, ok := env.Data["token"]; !ok {
+		t.Errorf("expected token variable in base environment, got %v", env.Data)
+	}
+
+	folder, ok := byName["request_group users"]
+	if !ok || *folder.ParentID != ws.ID {
+		t.Fatalf("expected users folder under workspace, got %+v", folder)
+	}
+
+	get := byName["request GET /users/{id}"]
+	if *get.ParentID != folder.ID {
+		t.Errorf("expected GET /users/{id} inside users folder")
+	}
+	if get.URL != "{{ _.baseUrl }}/users/:id" {
+		t.Errorf("unexpected url %q", get.URL)
+	}
+	if len(get.PathParameters) != 1 || get.PathParameters[0].Name != "id" {
+		t.Errorf("expected id path parameter, got %+v", get.PathParameters)
+	}
+	if len(get.Parameters) != 1 || !get.Parameters[0].Disabled {
+		t.Errorf("expected disabled fields parameter, got %+v", get.Parameters)
+	}
+
+	post := byName["request POST /users"]
+	if post.Body == nil || post.Body.MimeType != "application/json" || post.Body.Text != `{"name":"string"}` {
+		t.Errorf("unexpected body %+v", post.Body)
+	}
+	if post.Authentication["type"] != "bearer" || post.Authentication["token"] != "{{ _.token }}" {
+		t.Errorf("expected bearer auth, got %v", post.Authentication)
+	}
+
+	if _, err := json.Marshal(exp); err != nil {
+		t.Fatal(err)
+	}
+
+	again := Build(postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080", GroupDepth: 1}, eps)
+	for i := range exp.Resources {
+		if exp.Resources[i].ID != again.Resources[i].ID {
+			t.Errorf("resource ids are not stable: %s != %s", exp.Resources[i].ID, again.Resources[i].ID)
+		}
+	}
+}
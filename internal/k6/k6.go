@@ -0,0 +1,253 @@
+// Package k6 renders scanned endpoints as a k6 load-test script. Every
+// endpoint becomes a function sending one http.request inside a group;
+// readOnly() runs the GET/HEAD ones and readWrite() runs all of them.
+package k6
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// Options configures the generated scenario; VUS, DURATION and READ_ONLY
+// environment variables override them when the script runs
+type Options struct {
+	VUs      int
+	Duration string
+	ReadOnly bool // run only GET/HEAD endpoints by default
+}
+
+type request struct {
+	fn       string
+	readOnly bool
+	code     string
+}
+
+// Build renders the script, with endpoints in the order of the Postman tree
+// built with the same options
+func Build(opts postman.BuildOpts, k6 Options, eps []scan.Endpoint) string {
+	if k6.VUs <= 0 {
+		k6.VUs = 1
+	}
+	if k6.Duration == "" {
+		k6.Duration = "30s"
+	}
+	col := postman.BuildCollection(opts, eps)
+
+	g := &generator{used: make(map[string]bool)}
+	postman.Walk(col.Item, col.Auth, g.request)
+
+	vars := map[string]bool{"baseUrl": true}
+	for _, r := range g.requests {
+		for _, m := range jsVariable.FindAllStringSubmatch(r.code, -1) {
+			vars[m[1]] = true
+		}
+	}
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("// Generated by postman-gen.\n")
+	b.WriteString("//   k6 run script.js\n")
+	b.WriteString("//   k6 run -e BASE_URL=https://staging.example.com -e VUS=50 -e DURATION=5m -e READ_ONLY=true script.js\n")
+	b.WriteString("import http from 'k6/http';\n")
+	if g.usesEncoding {
+		b.WriteString("import encoding from 'k6/encoding';\n")
+	}
+	b.WriteString("import { check, group } from 'k6';\n\n")
+
+	b.WriteString("const vars = {\n")
+	for _, name := range names {
+		def := ""
+		if name == "baseUrl" {
+			def = opts.BaseURL
+		}
+		fmt.Fprintf(&b, "  %s: __ENV.%s || %s,\n", name, postman.EnvName(name), jsString(def))
+	}
+	b.WriteString("};\n\n")
+
+	fmt.Fprintf(&b, "const readOnlyDefault = %t;\n", k6.ReadOnly)
+	b.WriteString("const readOnlyMode = __ENV.READ_ONLY ? __ENV.READ_ONLY === 'true' : readOnlyDefault;\n\n")
+	b.WriteString("export const options = {\n")
+	b.WriteString("  scenarios: {\n")
+	b.WriteString("    api: {\n")
+	b.WriteString("      executor: 'constant-vus',\n")
+	fmt.Fprintf(&b, "      vus: Number(__ENV.VUS || %d),\n", k6.VUs)
+	fmt.Fprintf(&b, "      duration: __ENV.DURATION || %s,\n", jsString(k6.Duration))
+	b.WriteString("      exec: readOnlyMode ? 'readOnly' : 'readWrite',\n")
+	b.WriteString("    },\n")
+	b.WriteString("  },\n")
+	b.WriteString("};\n")
+
+	for _, r := range g.requests {
+		b.WriteString("\n" + r.code)
+	}
+
+	b.WriteString("\n// GET and HEAD endpoints only\nexport function readOnly() {\n")
+	for _, r := range g.requests {
+		if r.readOnly {
+			b.WriteString("  " + r.fn + "();\n")
+		}
+	}
+	b.WriteString("}\n\n// Every endpoint, including the ones that change data\nexport function readWrite() {\n")
+	for _, r := range g.requests {
+		b.WriteString("  " + r.fn + "();\n")
+	}
+	b.WriteString("}\n\nexport default function () {\n  if (readOnlyMode) {\n    readOnly();\n  } else {\n    readWrite();\n  }\n}\n")
+	return b.String()
+}
+
+type generator struct {
+	requests     []request
+	used         map[string]bool
+	usesEncoding bool
+}
+
+func (g *generator) request(it postman.Item, auth *postman.Auth) {
+	r := it.Request
+
+	// Routes registered for any method are sent as GET
+	method := strings.ToUpper(r.Method)
+	if method == "ANY" || method == "" {
+		method = http.MethodGet
+	}
+
+	fn := funcName(method, strings.SplitN(r.URL.Raw, "?", 2)[0])
+	base := fn
+	for n := 2; g.used[fn]; n++ {
+		fn = base + strconv.Itoa(n)
+	}
+	g.used[fn] = true
+
+	// Header values are rendered as JS expressions. The collection keeps
+	// annotation headers in map order, so they are sorted by name.
+	sorted := append([]postman.Header(nil), r.Header...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Key < sorted[j].Key })
+	var headers []postman.Header
+	if h := g.authHeader(auth); h != nil {
+		headers = append(headers, *h)
+	}
+	for _, h := range sorted {
+		headers = append(headers, postman.Header{Key: h.Key, Value: jsTemplate(h.Value)})
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "function %s() {\n", fn)
+	fmt.Fprintf(&b, "  group(%s, () => {\n", jsString(it.Name))
+	fmt.Fprintf(&b, "    const res = http.request(%s, %s, %s, {\n", jsString(method), jsTemplate(r.URL.Expand()), requestBody(r.Body))
+	b.WriteString("      headers: {\n")
+	for _, h := range headers {
+		fmt.Fprintf(&b, "        %s: %s,\n", jsString(h.Key), h.Value)
+	}
+	b.WriteString("      },\n")
+	fmt.Fprintf(&b, "      tags: { name: %s },\n", jsString(it.Name))
+	b.WriteString("    });\n")
+	label, cond := statusCheck(it.Response)
+	fmt.Fprintf(&b, "    check(res, { %s: (r) => %s });\n", jsString(label), cond)
+	b.WriteString("  });\n}\n")
+
+	g.requests = append(g.requests, request{
+		fn:       fn,
+		readOnly: method == http.MethodGet || method == http.MethodHead,
+		code:     b.String(),
+	})
+}
+
+// authHeader turns auth into the header k6 has to send, with the value as
+// a JS expression; basic credentials are encoded at runtime. Digest auth
+// can't be sent as a plain header and is left out.
+func (g *generator) authHeader(a *postman.Auth) *postman.Header {
+	if a == nil {
+		return nil
+	}
+	m := a.Attrs()
+	switch a.Type {
+	case "bearer":
+		return &postman.Header{Key: "Authorization", Value: jsTemplate("Bearer " + m["token"])}
+	case "oauth2":
+		return &postman.Header{Key: "Authorization", Value: jsTemplate("Bearer " + m["accessToken"])}
+	case "apikey":
+		return &postman.Header{Key: m["key"], Value: jsTemplate(m["value"])}
+	case "basic":
+		g.usesEncoding = true
+		creds := jsTemplate(m["username"] + ":" + m["password"])
+		return &postman.Header{Key: "Authorization", Value: "`Basic ${encoding.b64encode(" + creds + ")}`"}
+	}
+	return nil
+}
+
+// statusCheck expects one of the detected success codes; without any the
+// check only asserts that the server did not fail
+func statusCheck(responses []postman.Response) (string, string) {
+	var codes []int
+	seen := make(map[int]bool)
+	for _, r := range responses {
+		if r.Code >= 200 && r.Code < 300 && !seen[r.Code] {
+			seen[r.Code] = true
+			codes = append(codes, r.Code)
+		}
+	}
+	switch len(codes) {
+	case 0:
+		return "status is not 5xx", "r.status < 500"
+	case 1:
+		return "status is " + strconv.Itoa(codes[0]), "r.status === " + strconv.Itoa(codes[0])
+	}
+	var list []string
+	for _, c := range codes {
+		list = append(list, strconv.Itoa(c))
+	}
+	return "status is " + strings.Join(list, " or "), "[" + strings.Join(list, ", ") + "].includes(r.status)"
+}
+
+func requestBody(body *postman.Body) string {
+	if body == nil || body.Raw == "" {
+		return "null"
+	}
+	var pretty bytes.Buffer
+	if json.Indent(&pretty, []byte(body.Raw), "      ", "  ") == nil {
+		return "JSON.stringify(" + pretty.String() + ")"
+	}
+	return jsString(body.Raw)
+}
+
+var (
+	variableRe = regexp.MustCompile(`\{\{([A-Za-z0-9_]+)\}\}`)
+	jsVariable = regexp.MustCompile(`\$\{vars\.([A-Za-z0-9_]+)\}`)
+)
+
+// jsTemplate turns s into a JS string, with {{var}} references read from
+// the vars object
+func jsTemplate(s string) string {
+	if !variableRe.MatchString(s) {
+		return jsString(s)
+	}
+	escaped := strings.NewReplacer("\\", "\\\\", "`", "\\`", "${", "\\${").Replace(s)
+	return "`" + variableRe.ReplaceAllString(escaped, "$${vars.$1}") + "`"
+}
+
+func jsString(s string) string {
+	data, _ := json.Marshal(s)
+	return "'" + strings.ReplaceAll(strings.ReplaceAll(string(data[1:len(data)-1]), `\"`, `"`), "'", `\'`) + "'"
+}
+
+// funcName builds a JS identifier from the method and path,
+// e.g. getV1UsersId for GET {{baseUrl}}/v1/users/:id
+func funcName(method, rawURL string) string {
+	words := postman.RouteWords(method, rawURL)
+	for i, w := range words[1:] {
+		words[i+1] = strings.ToUpper(w[:1]) + w[1:]
+	}
+	return strings.Join(words, "")
+}
@@ -0,0 +1,62 @@
+package k6
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/postman"
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func TestBuild(t *testing.T) {
+	eps := []scan.Endpoint{
+		{
+			Method: "GET", Path: "/v1/users/{id}",
+			RequestHeaders: []scan.HeaderParam{{Name: "X-Tenant-ID"}},
+			Responses:      []scan.ResponseExample{{Status: 200}, {Status: 404}},
+		},
+		{
+			Method: "POST", Path: "/v1/users", BodyRaw: `{"name":"O'Brien"}`,
+			Auth:      &scan.AuthInfo{Type: "bearer"},
+			Responses: []scan.ResponseExample{{Status: 201}, {Status: 200}},
+		},
+		{Method: "DELETE", Path: "/v1/users/{id}"},
+	}
+	got := Build(postman.BuildOpts{Name: "API", BaseURL: "http://localhost:8080"}, Options{VUs: 5, Duration: "1m", ReadOnly: true}, eps)
+
+	for _, s := range []string{
+		"  baseUrl: __ENV.BASE_URL || 'http://localhost:8080',\n  tenantId: __ENV.TENANT_ID || '',\n  token: __ENV.TOKEN || '',\n",
+		"const readOnlyDefault = true;",
+		"vus: Number(__ENV.VUS || 5),",
+		"duration: __ENV.DURATION || '1m',",
+		"function getV1UsersId() {\n  group('GET /v1/users/{id}', () => {\n" +
+			"    const res = http.request('GET', `${vars.baseUrl}/v1/users/1`, null, {\n",
+		"        'X-Tenant-ID': `${vars.tenantId}`,\n",
+		"check(res, { 'status is 200': (r) => r.status === 200 });",
+		"'Authorization': `Bearer ${vars.token}`,",
+		"check(res, { 'status is 201 or 200': (r) => [201, 200].includes(r.status) });",
+		`"name": "O'Brien"`,
+		"check(res, { 'status is not 5xx': (r) => r.status < 500 });",
+		"export function readOnly() {\n  getV1UsersId();\n}\n",
+		"export function readWrite() {\n  postV1Users();\n  deleteV1UsersId();\n  getV1UsersId();\n}\n",
+	} {
+		if !strings.Contains(got, s) {
+			t.Errorf("missing %q in:\n%s", s, got)
+		}
+	}
+	if strings.Contains(got, "k6/encoding") {
+		t.Error("encoding import is only needed for basic auth")
+	}
+}
+
+func TestHelpers(t *testing.T) {
+	if got := funcName("GET", "{{baseUrl}}/v1/users/:id"); got != "getV1UsersId" {
+		t.Errorf("funcName = %q", got)
+	}
+	if got := jsString(`it's "quoted"`); got != `'it\'s "quoted"'` {
+		t.Errorf("jsString = %q", got)
+	}
+	if got := jsTemplate("Bearer {{token}} `x`"); got != "`Bearer ${vars.token} \\`x\\``" {
+		t.Errorf("jsTemplate = %q", got)
+	}
+}
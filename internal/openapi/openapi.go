@@ -0,0 +1,467 @@
+// Package openapi converts scanned endpoints into an OpenAPI 3.1 document.
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+const Version = "3.1.0"
+
+type Options struct {
+	Title     string
+	Version   string
+	ServerURL string
+	// Structs are the project's struct definitions (ProjectAnalysis.Structs),
+	// used for components/schemas
+	Structs map[string]*scan.StructDefinition
+	// Types and Constants (ProjectAnalysis.Types and .Constants) describe
+	// named types such as type OrderStatus string and their allowed values
+	Types     map[string]*scan.TypeDefinition
+	Constants map[string][]scan.ConstValue
+}
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// PathItem maps lower-case HTTP methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+
+	// AnyMethod marks an operation of a route registered for any method
+	AnyMethod bool `json:"x-any-method,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+	Example     any     `json:"example,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema  *Schema `json:"schema,omitempty"`
+	Example any     `json:"example,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme,omitempty"`
+	Name   string `json:"name,omitempty"`
+	In     string `json:"in,omitempty"`
+}
+
+// Build converts endpoints into an OpenAPI document. Endpoints registered
+// for any method (ANY) are documented under every method in anyMethods the
+// path has no other endpoint for.
+func Build(opts Options, eps []scan.Endpoint) *Document {
+	if opts.Version == "" {
+		opts.Version = "1.0.0"
+	}
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: opts.Title, Version: opts.Version},
+		Paths:   make(map[string]PathItem),
+	}
+	if opts.ServerURL != "" {
+		doc.Servers = []Server{{URL: opts.ServerURL}}
+	}
+
+	b := &builder{
+		structs:   opts.Structs,
+		types:     opts.Types,
+		constants: opts.Constants,
+		schemas:   make(map[string]*Schema),
+		security:  make(map[string]*SecurityScheme),
+		opIDs:     make(map[string]bool),
+	}
+
+	sorted := append([]scan.Endpoint(nil), eps...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Path == sorted[j].Path {
+			// ANY takes the methods left after the explicit ones
+			if anyI, anyJ := isAny(sorted[i]), isAny(sorted[j]); anyI != anyJ {
+				return anyJ
+			}
+			return sorted[i].Method < sorted[j].Method
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	tags := make(map[string]bool)
+	for _, e := range sorted {
+		path, params := openAPIPath(e.Path)
+		methods := []string{strings.ToLower(e.Method)}
+		if isAny(e) {
+			methods = anyMethods
+		}
+		item := doc.Paths[path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[path] = item
+		}
+		for _, method := range methods {
+			if _, exists := item[method]; exists {
+				continue // same route registered twice (e.g. in several files)
+			}
+			item[method] = b.operation(e, method, path, params)
+		}
+		for _, t := range e.Tags {
+			tags[t] = true
+		}
+	}
+
+	for _, t := range sortedKeys(tags) {
+		doc.Tags = append(doc.Tags, Tag{Name: t})
+	}
+	if len(b.schemas) > 0 || len(b.security) > 0 {
+		doc.Components = &Components{}
+		if len(b.schemas) > 0 {
+			doc.Components.Schemas = b.schemas
+		}
+		if len(b.security) > 0 {
+			doc.Components.SecuritySchemes = b.security
+		}
+	}
+	return doc
+}
+
+// anyMethods document an endpoint registered for any method
+var anyMethods = []string{"get", "post", "put", "patch", "delete"}
+
+func isAny(e scan.Endpoint) bool {
+	return strings.EqualFold(e.Method, "ANY")
+}
+
+// builder accumulates components while operations are built
+type builder struct {
+	structs   map[string]*scan.StructDefinition
+	types     map[string]*scan.TypeDefinition
+	constants map[string][]scan.ConstValue
+	schemas   map[string]*Schema
+	security  map[string]*SecurityScheme
+	opIDs     map[string]bool
+}
+
+func (b *builder) operation(e scan.Endpoint, method, path string, pathParams []scan.PathParam) *Operation {
+	op := &Operation{
+		OperationID: b.operationID(e.Handler, method, path),
+		Summary:     e.Desc,
+		Tags:        e.Tags,
+		Responses:   make(map[string]*Response),
+	}
+	if isAny(e) {
+		op.Description = "Accepts any HTTP method."
+		op.AnyMethod = true
+	}
+
+	for _, pp := range pathParams {
+		p := Parameter{
+			Name:        pp.Name,
+			In:          "path",
+			Description: pp.Description(),
+			Required:    true,
+			Schema:      &Schema{Type: "string"},
+			Example:     pp.ExampleValue(),
+		}
+		if doc, ok := e.PathParams[pp.Name]; ok {
+			if doc.Description != "" {
+				p.Description = doc.Description
+			}
+			if doc.Example != "" {
+				p.Example = doc.Example
+			}
+		}
+		op.Parameters = append(op.Parameters, p)
+	}
+	for _, q := range e.Query {
+		p := Parameter{Name: q.Name, In: "query", Description: q.Description, Required: q.Required, Schema: &Schema{Type: "string"}}
+		if q.Default != "" {
+			p.Schema.Default = q.Default
+		}
+		if q.Example != "" {
+			p.Example = q.Example
+		}
+		op.Parameters = append(op.Parameters, p)
+	}
+	op.Parameters = append(op.Parameters, headerParameters(e)...)
+
+	op.RequestBody = b.requestBody(e)
+
+	for _, r := range e.Responses {
+		code := "default"
+		if r.Status != 0 {
+			code = strconv.Itoa(r.Status)
+		}
+		if _, exists := op.Responses[code]; exists {
+			continue
+		}
+		op.Responses[code] = b.response(r)
+	}
+	if len(op.Responses) == 0 {
+		op.Responses["default"] = &Response{Description: "Default response"}
+	}
+
+	if name := b.securityScheme(e.Auth); name != "" {
+		op.Security = []map[string][]string{{name: {}}}
+	}
+	return op
+}
+
+// operationID uses the handler name, falling back to method and path, and
+// keeps ids unique across the document
+func (b *builder) operationID(handler, method, path string) string {
+	id := handler
+	if id == "" || b.opIDs[id] {
+		id = methodPathID(method, path)
+	}
+	base := id
+	for i := 2; b.opIDs[id]; i++ {
+		id = base + strconv.Itoa(i)
+	}
+	b.opIDs[id] = true
+	return id
+}
+
+// methodPathID builds ids like getV1UsersId
+func methodPathID(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(strings.ToLower(method))
+	upper := true
+	for _, r := range path {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// headerParameters lists annotated and detected headers and cookies.
+// Accept, Content-Type and Authorization are described by OpenAPI itself.
+func headerParameters(e scan.Endpoint) []Parameter {
+	reserved := map[string]bool{"accept": true, "content-type": true, "authorization": true, "host": true}
+	seen := make(map[string]bool)
+	var params []Parameter
+
+	annotated := make([]string, 0, len(e.Headers))
+	for k := range e.Headers {
+		annotated = append(annotated, k)
+	}
+	sort.Strings(annotated)
+	for _, k := range annotated {
+		lower := strings.ToLower(k)
+		if reserved[lower] || seen["header:"+lower] {
+			continue
+		}
+		seen["header:"+lower] = true
+		params = append(params, Parameter{Name: k, In: "header", Schema: &Schema{Type: "string"}, Example: e.Headers[k]})
+	}
+
+	for _, h := range e.RequestHeaders {
+		in, key := "header", "header:"+strings.ToLower(h.Name)
+		if h.Cookie {
+			in, key = "cookie", "cookie:"+h.Name
+		}
+		if (!h.Cookie && reserved[strings.ToLower(h.Name)]) || seen[key] {
+			continue
+		}
+		seen[key] = true
+		params = append(params, Parameter{Name: h.Name, In: in, Description: h.Description, Schema: &Schema{Type: "string"}})
+	}
+	return params
+}
+
+func (b *builder) requestBody(e scan.Endpoint) *RequestBody {
+	if e.Type == "GraphQL" {
+		example := map[string]any{}
+		if e.GraphQL != nil && e.GraphQL.Query != "" {
+			example["query"] = e.GraphQL.Query
+		}
+		return &RequestBody{Required: true, Content: map[string]MediaType{
+			"application/json": {
+				Schema: &Schema{Type: "object", Properties: map[string]*Schema{
+					"query":     {Type: "string"},
+					"variables": {Type: "object"},
+				}, Required: []string{"query"}},
+				Example: example,
+			},
+		}}
+	}
+	if e.BodyRaw == "" {
+		return nil
+	}
+
+	media := MediaType{}
+	if example, ok := parseExample(e.BodyRaw); ok {
+		media.Example = example
+		media.Schema = schemaFromExample(example)
+	} else {
+		media.Schema = &Schema{Type: "string"}
+		media.Example = e.BodyRaw
+	}
+	if ref := b.typeSchema(e.BodyType); ref != nil {
+		media.Schema = ref
+	}
+	return &RequestBody{Required: true, Content: map[string]MediaType{"application/json": media}}
+}
+
+func (b *builder) response(r scan.ResponseExample) *Response {
+	resp := &Response{Description: http.StatusText(r.Status)}
+	if resp.Description == "" {
+		resp.Description = "Response"
+	}
+	if r.ContentType == "" {
+		return resp
+	}
+
+	media := MediaType{}
+	switch r.ContentType {
+	case "application/json":
+		if example, ok := parseExample(r.Body); ok {
+			media.Example = example
+			media.Schema = schemaFromExample(example)
+		}
+		if ref := b.typeSchema(r.Type); ref != nil {
+			media.Schema = ref
+		}
+	default:
+		media.Schema = &Schema{Type: "string"}
+		if r.Body != "" {
+			media.Example = r.Body
+		}
+	}
+	resp.Content = map[string]MediaType{r.ContentType: media}
+	return resp
+}
+
+// securityScheme registers the scheme matching auth and returns its name
+func (b *builder) securityScheme(a *scan.AuthInfo) string {
+	if a == nil {
+		return ""
+	}
+	var name string
+	var scheme *SecurityScheme
+	switch a.Type {
+	case "bearer", "oauth2":
+		name, scheme = "bearerAuth", &SecurityScheme{Type: "http", Scheme: "bearer"}
+	case "basic":
+		name, scheme = "basicAuth", &SecurityScheme{Type: "http", Scheme: "basic"}
+	case "digest":
+		name, scheme = "digestAuth", &SecurityScheme{Type: "http", Scheme: "digest"}
+	case "apikey":
+		key := a.Key
+		if key == "" {
+			key = "X-API-Key"
+		}
+		name, scheme = "apiKey_"+key, &SecurityScheme{Type: "apiKey", Name: key, In: "header"}
+	default:
+		return ""
+	}
+	b.security[name] = scheme
+	return name
+}
+
+// openAPIPath turns any router path syntax into /users/{id}. Segments the
+// scanner could not resolve ({{Prefix}}) become path parameters too.
+func openAPIPath(p string) (string, []scan.PathParam) {
+	normalized, params := scan.NormalizePath(p)
+	segments := strings.Split(normalized, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") {
+			segments[i] = "{" + seg[1:] + "}"
+		}
+	}
+	path := placeholderRe.ReplaceAllStringFunc(strings.Join(segments, "/"), func(ref string) string {
+		name := placeholderRe.FindStringSubmatch(ref)[1]
+		declared := false
+		for _, pp := range params {
+			declared = declared || pp.Name == name
+		}
+		if !declared {
+			params = append(params, scan.PathParam{Name: name, Original: ref})
+		}
+		return "{" + name + "}"
+	})
+	return path, params
+}
+
+var placeholderRe = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+
+// parseExample decodes a JSON example, keeping integers distinguishable
+// from floats
+func parseExample(raw string) (any, bool) {
+	dec := json.NewDecoder(strings.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+	return v, true
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
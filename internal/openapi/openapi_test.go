@@ -0,0 +1,246 @@
+package openapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func testStructs() map[string]*scan.StructDefinition {
+	return map[string]*scan.StructDefinition{
+		"models.Base": {Name: "Base", Package: "models", Fields: []scan.StructFieldInfo{
+			{Name: "ID", Type: "int64", JSONTag: "id"},
+		}},
+		"models.User": {Name: "User", Package: "models", Fields: []scan.StructFieldInfo{
+			{Name: "Base", Type: "Base", Embedded: true},
+			{Name: "Email", Type: "string", JSONTag: "email", Tag: `json:"email" binding:"required"`},
+			{Name: "Roles", Type: "[]string", JSONTag: "roles"},
+			{Name: "Manager", Type: "*User", JSONTag: "manager"},
+		}},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	eps := []scan.Endpoint{
+		{
+			Method: "POST", Path: "/v1/users", Handler: "createUser", Tags: []string{"users"},
+			BodyRaw: `{"email":"string","roles":["string"]}`, BodyType: "models.User",
+			Responses: []scan.ResponseExample{
+				{Status: 201, ContentType: "application/json", Body: `{"id":0}`, Type: "models.User"},
+				{Status: 400, ContentType: "text/plain", Body: "bad request"},
+			},
+			Auth: &scan.AuthInfo{Type: "bearer"},
+		},
+		{
+			Method: "GET", Path: "/v1/users/{id:[0-9]+}", Handler: "createUser", Tags: []string{"users"},
+			Query:          []scan.QueryParam{{Name: "fields", Default: "all"}},
+			RequestHeaders: []scan.HeaderParam{{Name: "X-Tenant-ID"}, {Name: "session", Cookie: true}},
+		},
+		{Method: "GET", Path: "/health", Desc: "Health check"},
+	}
+	doc := Build(Options{Title: "Users", ServerURL: "http://localhost:8080", Structs: testStructs()}, eps)
+
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("expected openapi 3.1.0, got %s", doc.OpenAPI)
+	}
+
+	create := doc.Paths["/v1/users"]["post"]
+	if create == nil {
+		t.Fatalf("missing POST /v1/users in %v", doc.Paths)
+	}
+	if create.OperationID != "createUser" {
+		t.Errorf("expected operationId createUser, got %s", create.OperationID)
+	}
+	if ref := create.RequestBody.Content["application/json"].Schema.Ref; ref != "#/components/schemas/User" {
+		t.Errorf("expected body $ref to User, got %q", ref)
+	}
+	if create.Responses["201"] == nil || create.Responses["400"] == nil {
+		t.Errorf("expected 201 and 400 responses, got %v", create.Responses)
+	}
+	if len(create.Security) != 1 || doc.Components.SecuritySchemes["bearerAuth"] == nil {
+		t.Errorf("expected bearerAuth security, got %v", create.Security)
+	}
+
+	user := doc.Components.Schemas["User"]
+	if user == nil {
+		t.Fatalf("missing User schema in %v", doc.Components.Schemas)
+	}
+	for _, prop := range []string{"id", "email", "roles", "manager"} {
+		if user.Properties[prop] == nil {
+			t.Errorf("User schema missing property %s", prop)
+		}
+	}
+	if user.Properties["manager"].Ref != "#/components/schemas/User" {
+		t.Errorf("expected self reference for manager, got %+v", user.Properties["manager"])
+	}
+	if len(user.Required) != 1 || user.Required[0] != "email" {
+		t.Errorf("expected email to be required, got %v", user.Required)
+	}
+
+	get := doc.Paths["/v1/users/{id}"]["get"]
+	if get == nil {
+		t.Fatalf("missing GET /v1/users/{id} in %v", doc.Paths)
+	}
+	var ins []string
+	for _, p := range get.Parameters {
+		ins = append(ins, p.In+":"+p.Name)
+	}
+	if got := strings.Join(ins, ","); got != "path:id,query:fields,header:X-Tenant-ID,cookie:session" {
+		t.Errorf("unexpected parameters %s", got)
+	}
+
+	// without a free handler name the id falls back to method and path
+	if id := get.OperationID; id != "getV1UsersId" {
+		t.Errorf("expected operationId getV1UsersId, got %s", id)
+	}
+	if id := doc.Paths["/health"]["get"].OperationID; id != "getHealth" {
+		t.Errorf("expected operationId getHealth, got %s", id)
+	}
+	if len(doc.Tags) != 1 || doc.Tags[0].Name != "users" {
+		t.Errorf("expected users tag, got %v", doc.Tags)
+	}
+}
+
+func TestMarshalYAML(t *testing.T) {
+	doc := Build(Options{Title: "Users API"}, []scan.Endpoint{
+		{Method: "GET", Path: "/users/:id", Handler: "getUser", Tags: []string{"users"},
+			Responses: []scan.ResponseExample{{Status: 200, ContentType: "application/json", Body: `{"name":"yes"}`}}},
+	})
+	data, err := doc.MarshalYAML()
+	if err != nil {
+		t.Fatalf("MarshalYAML: %v", err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"openapi: 3.1.0\n",
+		"  title: Users API\n",
+		"  /users/{id}:\n",
+		"      operationId: getUser\n",
+		"        - name: id\n          in: path\n",
+		"                name: \"yes\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected YAML to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	// the JSON form stays valid
+	js, err := doc.MarshalIndent()
+	if err != nil || !json.Valid(js) {
+		t.Errorf("invalid JSON output: %v", err)
+	}
+}
+
+func TestWellKnownTypeSchemas(t *testing.T) {
+	structs := map[string]*scan.StructDefinition{
+		"models.Event": {Name: "Event", Package: "models", Fields: []scan.StructFieldInfo{
+			{Name: "ID", Type: "uuid.UUID", JSONTag: "id"},
+			{Name: "At", Type: "*time.Time", JSONTag: "at"},
+			{Name: "Closed", Type: "null.Time", JSONTag: "closed"},
+			{Name: "Payload", Type: "[]byte", JSONTag: "payload"},
+		}},
+	}
+	eps := []scan.Endpoint{{Method: "POST", Path: "/events", BodyRaw: "{}", BodyType: "models.Event"}}
+	doc := Build(Options{Title: "Events", Structs: structs}, eps)
+
+	data, err := json.Marshal(doc.Components.Schemas["Event"].Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"at":{"type":"string","format":"date-time"},` +
+		`"closed":{"type":["string","null"],"format":"date-time"},` +
+		`"id":{"type":"string","format":"uuid"},` +
+		`"payload":{"type":"string","format":"byte"}}`
+	if string(data) != want {
+		t.Errorf("properties = %s", data)
+	}
+}
+
+func TestValidatorRuleSchemas(t *testing.T) {
+	structs := map[string]*scan.StructDefinition{
+		"models.Post": {Name: "Post", Package: "models", Fields: []scan.StructFieldInfo{
+			{Name: "Email", Type: "string", JSONTag: "email", Tag: `json:"email" validate:"required,email"`},
+			{Name: "Title", Type: "string", JSONTag: "title", Tag: `json:"title" binding:"min=3,max=50"`},
+			{Name: "Status", Type: "string", JSONTag: "status", Tag: `json:"status" validate:"oneof=draft published"`},
+			{Name: "Page", Type: "int", JSONTag: "page", Tag: `json:"page" validate:"gte=1"`},
+		}},
+	}
+	eps := []scan.Endpoint{{Method: "POST", Path: "/posts", BodyRaw: "{}", BodyType: "models.Post"}}
+	doc := Build(Options{Title: "Posts", Structs: structs}, eps)
+
+	post := doc.Components.Schemas["Post"]
+	data, err := json.Marshal(post.Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"email":{"type":"string","format":"email","description":"Required. Email address."},` +
+		`"page":{"type":"integer","format":"int32","description":"Minimum 1.","minimum":1},` +
+		`"status":{"type":"string","description":"One of: draft, published.","enum":["draft","published"]},` +
+		`"title":{"type":"string","description":"Length 3 to 50.","minLength":3,"maxLength":50}}`
+	if string(data) != want {
+		t.Errorf("properties = %s", data)
+	}
+	if len(post.Required) != 1 || post.Required[0] != "email" {
+		t.Errorf("required = %v", post.Required)
+	}
+}
+
+func TestEnumSchemas(t *testing.T) {
+	structs := map[string]*scan.StructDefinition{
+		"models.Order": {Name: "Order", Package: "models", Fields: []scan.StructFieldInfo{
+			{Name: "Status", Type: "OrderStatus", JSONTag: "status", Tag: `json:"status"`},
+			{Name: "Priority", Type: "[]Priority", JSONTag: "priority", Tag: `json:"priority"`},
+		}},
+	}
+	types := map[string]*scan.TypeDefinition{
+		"models.OrderStatus": {Name: "OrderStatus", Package: "models", UnderlyingType: "string"},
+		"models.Priority":    {Name: "Priority", Package: "models", UnderlyingType: "int"},
+	}
+	constants := map[string][]scan.ConstValue{
+		"models.OrderStatus": {{Name: "StatusPending", Value: `"pending"`}, {Name: "StatusPaid", Value: `"paid"`}},
+		"models.Priority":    {{Name: "PriorityLow", Value: "0"}, {Name: "PriorityHigh", Value: "1"}},
+	}
+	eps := []scan.Endpoint{{Method: "POST", Path: "/orders", BodyRaw: "{}", BodyType: "models.Order"}}
+	doc := Build(Options{Title: "Orders", Structs: structs, Types: types, Constants: constants}, eps)
+
+	data, err := json.Marshal(doc.Components.Schemas["Order"].Properties)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"priority":{"type":"array","items":{"type":"integer","format":"int32","enum":[0,1]}},` +
+		`"status":{"type":"string","enum":["pending","paid"]}}`
+	if string(data) != want {
+		t.Errorf("properties = %s", data)
+	}
+}
+
+func TestBuild_PlaceholderSegmentsAndAnyMethod(t *testing.T) {
+	eps := []scan.Endpoint{
+		{Method: "ANY", Path: "/{{Prefix}}/dynamic", Handler: "dynamic"},
+		{Method: "POST", Path: "/{{Prefix}}/dynamic", Handler: "createDynamic"},
+	}
+	doc := Build(Options{Title: "API"}, eps)
+
+	item, ok := doc.Paths["/{Prefix}/dynamic"]
+	if !ok {
+		t.Fatalf("expected path /{Prefix}/dynamic, got %v", doc.Paths)
+	}
+	if len(item) != len(anyMethods) {
+		t.Errorf("expected %d operations, got %d", len(anyMethods), len(item))
+	}
+	if op := item["post"]; op == nil || op.OperationID != "createDynamic" || op.AnyMethod {
+		t.Errorf("expected the explicit POST endpoint, got %+v", op)
+	}
+	get := item["get"]
+	if get == nil || get.OperationID != "dynamic" || !get.AnyMethod {
+		t.Fatalf("expected the ANY endpoint under get, got %+v", get)
+	}
+	if del := item["delete"]; del == nil || !del.AnyMethod {
+		t.Errorf("expected the ANY endpoint under delete, got %+v", del)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].Name != "Prefix" || get.Parameters[0].In != "path" || !get.Parameters[0].Required {
+		t.Errorf("expected a Prefix path parameter, got %+v", get.Parameters)
+	}
+}
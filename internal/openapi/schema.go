@@ -0,0 +1,368 @@
+package openapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// Schema is the subset of JSON Schema used for bodies and parameters
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     *float64           `json:"exclusiveMaximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Nullable             bool               `json:"-"` // Type also allows null
+}
+
+// MarshalJSON writes a nullable type the OpenAPI 3.1 way, as [type, "null"]
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.Nullable || s.Type == "" {
+		return json.Marshal(plain(s))
+	}
+	return json.Marshal(struct {
+		Type []string `json:"type"`
+		plain
+	}{[]string{s.Type, "null"}, plain(s)})
+}
+
+// maxSchemaDepth bounds nesting of inline (unnamed) struct types
+const maxSchemaDepth = 8
+
+// typeSchema returns a $ref to the component for a scanned type name such as
+// "models.User" or "[]models.User", registering the component on first use
+func (b *builder) typeSchema(typeName string) *Schema {
+	if typeName == "" {
+		return nil
+	}
+	if elem, ok := strings.CutPrefix(typeName, "[]"); ok {
+		if items := b.typeSchema(elem); items != nil {
+			return &Schema{Type: "array", Items: items}
+		}
+		return nil
+	}
+	def, ok := b.structs[typeName]
+	if !ok {
+		return nil
+	}
+	return b.structRef(typeName, def)
+}
+
+// structRef registers def under components/schemas and references it. The
+// short name is used unless another project struct shares it.
+func (b *builder) structRef(key string, def *scan.StructDefinition) *Schema {
+	name := b.componentName(key, def)
+	ref := &Schema{Ref: "#/components/schemas/" + name}
+	if _, done := b.schemas[name]; done {
+		return ref
+	}
+	// placeholder first, so self-referencing structs terminate
+	b.schemas[name] = &Schema{Type: "object"}
+	b.schemas[name] = b.structSchema(def, 0)
+	return ref
+}
+
+func (b *builder) componentName(key string, def *scan.StructDefinition) string {
+	for otherKey, other := range b.structs {
+		if otherKey != key && other.Name == def.Name {
+			return key
+		}
+	}
+	return def.Name
+}
+
+// structSchema describes a struct's JSON encoding. Embedded structs are
+// flattened like encoding/json does.
+func (b *builder) structSchema(def *scan.StructDefinition, depth int) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	b.addFields(s, def, depth)
+	if len(s.Properties) == 0 {
+		s.Properties = nil
+	}
+	return s
+}
+
+func (b *builder) addFields(s *Schema, def *scan.StructDefinition, depth int) {
+	for _, f := range def.Fields {
+		if f.Embedded && f.JSONTag == "" {
+			// promoted fields are encoded inline
+			if _, embedded := b.lookup(strings.TrimPrefix(f.Type, "*"), def.Package); embedded != nil {
+				if depth < maxSchemaDepth {
+					b.addFields(s, embedded, depth+1)
+				}
+				continue
+			}
+		}
+		// json:"-," names a field "-" rather than skipping it
+		skip := f.JSONTag == "-" && !strings.HasPrefix(reflect.StructTag(f.Tag).Get("json"), "-,")
+		if skip || (f.Name != "" && !isExported(f.Name)) {
+			continue
+		}
+		name := f.JSONTag
+		if name == "" {
+			name = f.Name
+		}
+		prop := b.goTypeSchema(f.Type, def.Package, depth)
+		if hasJSONOption(f.Tag, "string") && (prop.Type == "integer" || prop.Type == "number" || prop.Type == "boolean") {
+			// ,string encodes the value inside a JSON string
+			prop = &Schema{Type: "string"}
+		}
+		rules := scan.ParseFieldRules(f.Tag)
+		applyRules(prop, rules)
+		s.Properties[name] = prop
+		if rules.Required {
+			s.Required = append(s.Required, name)
+		}
+	}
+	sort.Strings(s.Required)
+}
+
+// applyRules adds the validator rules of a field (binding and validate
+// tags) to its schema: a description marking it required, the format,
+// enum and bounds
+func applyRules(s *Schema, r scan.FieldRules) {
+	if r.IsZero() {
+		return
+	}
+	s.Description = r.Describe(s.Type)
+	if s.Ref != "" {
+		return
+	}
+	if s.Format == "" {
+		s.Format = validatorFormat(r)
+	}
+	if len(r.OneOf) > 0 {
+		s.Enum = nil // oneof narrows the constants of a named type
+	}
+	switch s.Type {
+	case "string":
+		for _, m := range r.OneOf {
+			s.Enum = append(s.Enum, m)
+		}
+		s.MinLength, s.MaxLength = lengthBounds(r)
+	case "integer", "number":
+		for _, m := range r.OneOf {
+			if n, err := strconv.ParseFloat(m, 64); err == nil {
+				s.Enum = append(s.Enum, n)
+			}
+		}
+		if r.MinExcl {
+			s.ExclusiveMinimum = r.Min
+		} else {
+			s.Minimum = r.Min
+		}
+		if r.MaxExcl {
+			s.ExclusiveMaximum = r.Max
+		} else {
+			s.Maximum = r.Max
+		}
+	case "array":
+		s.MinItems, s.MaxItems = lengthBounds(r)
+		if r.Dive != nil && s.Items != nil {
+			applyRules(s.Items, *r.Dive)
+		}
+	}
+}
+
+// lengthBounds are the inclusive length bounds of the rules
+func lengthBounds(r scan.FieldRules) (lo, hi *int) {
+	if r.Min != nil {
+		n := int(*r.Min)
+		if r.MinExcl {
+			n++
+		}
+		lo = &n
+	}
+	if r.Max != nil {
+		n := int(*r.Max)
+		if r.MaxExcl {
+			n--
+		}
+		hi = &n
+	}
+	return lo, hi
+}
+
+// validatorFormat maps a validator format tag to an OpenAPI format
+func validatorFormat(r scan.FieldRules) string {
+	switch r.Format {
+	case "email":
+		return "email"
+	case "uuid", "uuid3", "uuid4", "uuid5", "uuid_rfc4122", "uuid4_rfc4122":
+		return "uuid"
+	case "url", "http_url", "uri":
+		return "uri"
+	case "ipv4", "ip4_addr":
+		return "ipv4"
+	case "ipv6":
+		return "ipv6"
+	case "hostname", "hostname_rfc1123", "fqdn":
+		return "hostname"
+	case "datetime":
+		switch r.Layout {
+		case time.RFC3339, time.RFC3339Nano:
+			return "date-time"
+		case time.DateOnly:
+			return "date"
+		}
+	}
+	return ""
+}
+
+// hasJSONOption reports an option of the field's json tag, e.g. "string"
+func hasJSONOption(tag, option string) bool {
+	value, _ := reflect.StructTag(tag).Lookup("json")
+	_, opts, _ := strings.Cut(value, ",")
+	for _, opt := range strings.Split(opts, ",") {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+func isExported(name string) bool {
+	return name != "" && strings.ToUpper(name[:1]) == name[:1]
+}
+
+// lookup finds a struct referenced from package pkg as "User" or
+// "models.User"
+func (b *builder) lookup(typeName, pkg string) (string, *scan.StructDefinition) {
+	if !strings.Contains(typeName, ".") {
+		typeName = pkg + "." + typeName
+	}
+	if def, ok := b.structs[typeName]; ok {
+		return typeName, def
+	}
+	return "", nil
+}
+
+// goTypeSchema maps a Go type expression, as written in a struct field, to a
+// schema
+func (b *builder) goTypeSchema(goType, pkg string, depth int) *Schema {
+	goType = strings.TrimPrefix(goType, "*")
+	wk, ok := scan.LookupWellKnownType(goType)
+	if !ok && !strings.ContainsAny(goType, ".[") {
+		wk, ok = scan.LookupWellKnownType(pkg + "." + goType)
+	}
+	if ok {
+		return &Schema{Type: wk.Type, Format: wk.Format, Nullable: wk.Nullable}
+	}
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		elem := strings.TrimPrefix(goType, "[]")
+		return &Schema{Type: "array", Items: b.goTypeSchema(elem, pkg, depth+1)}
+	case strings.HasPrefix(goType, "map["):
+		return &Schema{Type: "object", AdditionalProperties: b.goTypeSchema(mapValueType(goType), pkg, depth+1)}
+	}
+
+	switch goType {
+	case "string":
+		return &Schema{Type: "string"}
+	case "bool":
+		return &Schema{Type: "boolean"}
+	case "int", "int8", "int16", "int32", "uint", "uint8", "uint16", "uint32", "byte", "rune":
+		return &Schema{Type: "integer", Format: "int32"}
+	case "int64", "uint64":
+		return &Schema{Type: "integer", Format: "int64"}
+	case "float32":
+		return &Schema{Type: "number", Format: "float"}
+	case "float64":
+		return &Schema{Type: "number", Format: "double"}
+	case "interface{}", "any":
+		return &Schema{}
+	}
+
+	if key, def := b.lookup(goType, pkg); def != nil {
+		return b.structRef(key, def)
+	}
+	if key, def := b.lookupType(goType, pkg); def != nil && depth < maxSchemaDepth {
+		s := b.goTypeSchema(def.UnderlyingType, def.Package, depth+1)
+		for _, c := range b.constants[key] {
+			s.Enum = append(s.Enum, json.RawMessage(c.Value))
+		}
+		return s
+	}
+	return &Schema{Type: "string"}
+}
+
+// lookupType finds a named non-struct type referenced from package pkg
+func (b *builder) lookupType(typeName, pkg string) (string, *scan.TypeDefinition) {
+	if !strings.Contains(typeName, ".") {
+		typeName = pkg + "." + typeName
+	}
+	if def, ok := b.types[typeName]; ok {
+		return typeName, def
+	}
+	return "", nil
+}
+
+// mapValueType returns V for map[K]V, honoring nested brackets in K
+func mapValueType(goType string) string {
+	depth := 0
+	for i, r := range goType {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return goType[i+1:]
+			}
+		}
+	}
+	return "string"
+}
+
+// schemaFromExample infers a schema from a decoded JSON example
+func schemaFromExample(v any) *Schema {
+	switch val := v.(type) {
+	case map[string]any:
+		s := &Schema{Type: "object"}
+		if len(val) > 0 {
+			s.Properties = make(map[string]*Schema, len(val))
+			for k, fv := range val {
+				s.Properties[k] = schemaFromExample(fv)
+			}
+		}
+		return s
+	case []any:
+		s := &Schema{Type: "array"}
+		if len(val) > 0 {
+			s.Items = schemaFromExample(val[0])
+		}
+		return s
+	case json.Number:
+		if strings.ContainsAny(val.String(), ".eE") {
+			return &Schema{Type: "number"}
+		}
+		return &Schema{Type: "integer"}
+	case string:
+		return &Schema{Type: "string"}
+	case bool:
+		return &Schema{Type: "boolean"}
+	case nil:
+		return &Schema{Type: "null"}
+	}
+	return &Schema{}
+}
@@ -0,0 +1,562 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// Spec is a hand-maintained OpenAPI 3 or Swagger 2.0 document (YAML or
+// JSON) whose summaries, parameters and examples enrich the scanned
+// endpoints. The code stays the source of truth for which routes exist.
+type Spec struct {
+	root       map[string]any
+	swagger2   bool
+	operations []*specOperation
+}
+
+type specOperation struct {
+	method     string // upper case
+	path       string // as written in the spec, prefixed with the server's base path
+	key        string // see routeKey
+	op         map[string]any
+	pathParams []any // parameters declared on the path item
+	matched    bool
+}
+
+// SpecReport lists the routes found on only one side
+type SpecReport struct {
+	Matched  int      // spec operations matched by at least one endpoint
+	SpecOnly []string // "GET /v1/legacy", as written in the spec
+	CodeOnly []string // "POST /v1/users/{id}", as registered in code
+}
+
+var specMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// LoadSpec reads an OpenAPI document from a .yaml, .yml or .json file
+func LoadSpec(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSpec(data)
+}
+
+// ParseSpec parses an OpenAPI document; JSON is detected by its leading
+// brace, anything else is read as YAML
+func ParseSpec(data []byte) (*Spec, error) {
+	var doc any
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.UseNumber()
+		if err := dec.Decode(&doc); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if doc, err = decodeYAML(data); err != nil {
+			return nil, err
+		}
+	}
+	root, ok := doc.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("not an OpenAPI document: top level is not a mapping")
+	}
+	paths, ok := root["paths"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("not an OpenAPI document: no paths")
+	}
+
+	s := &Spec{root: root}
+	if version, ok := root["swagger"]; ok {
+		// an unquoted 2.0 reads as the number 2
+		if v := exampleString(version); v != "2.0" && v != "2" {
+			return nil, fmt.Errorf("unsupported Swagger version %s: only 2.0 and OpenAPI 3 are supported", v)
+		}
+		s.swagger2 = true
+	}
+	base := s.basePath()
+	for _, p := range sortedMapKeys(paths) {
+		item := s.resolve(paths[p])
+		if item == nil {
+			continue
+		}
+		pathParams, _ := item["parameters"].([]any)
+		for _, method := range specMethods {
+			op := s.resolve(item[method])
+			if op == nil {
+				continue
+			}
+			full := base + p
+			s.operations = append(s.operations, &specOperation{
+				method:     strings.ToUpper(method),
+				path:       full,
+				key:        strings.ToUpper(method) + " " + routeKey(full),
+				op:         op,
+				pathParams: pathParams,
+			})
+		}
+	}
+	return s, nil
+}
+
+// basePath returns the path of the first server URL (/v1 for
+// https://api.example.com/v1), or Swagger 2.0's basePath, which prefixes
+// every spec path
+func (s *Spec) basePath() string {
+	if s.swagger2 {
+		base, _ := s.root["basePath"].(string)
+		return strings.TrimSuffix(base, "/")
+	}
+	servers, _ := s.root["servers"].([]any)
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]any)
+	raw, _ := server["url"].(string)
+	if strings.Contains(raw, "{") {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(u.Path, "/")
+}
+
+// routeKey normalizes a path for matching: every router syntax becomes
+// :name, parameter names are dropped and trailing slashes ignored, so
+// /users/{userId} matches /users/:id
+func routeKey(path string) string {
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	normalized, _ := scan.NormalizePath(path)
+	segments := strings.Split(normalized, "/")
+	for i, seg := range segments {
+		if strings.HasPrefix(seg, ":") {
+			segments[i] = ":"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// Enrich fills eps in place with what the spec documents about them and
+// reports the routes only one side knows. Routes registered for any
+// method match every operation on their path.
+func (s *Spec) Enrich(eps []scan.Endpoint) SpecReport {
+	var report SpecReport
+	codeOnly := make(map[string]bool)
+	for i := range eps {
+		e := &eps[i]
+		key := routeKey(e.Path)
+		matched := false
+		for _, so := range s.operations {
+			if so.key != e.Method+" "+key && (e.Method != "ANY" || !strings.HasSuffix(so.key, " "+key)) {
+				continue
+			}
+			so.matched = true
+			matched = true
+			s.apply(e, so)
+		}
+		if !matched {
+			codeOnly[e.Method+" "+e.Path] = true
+		}
+	}
+
+	for _, so := range s.operations {
+		if so.matched {
+			report.Matched++
+		} else {
+			report.SpecOnly = append(report.SpecOnly, so.method+" "+so.path)
+		}
+	}
+	report.CodeOnly = sortedKeys(codeOnly)
+	return report
+}
+
+// apply copies the spec's documentation into e. Summaries and
+// descriptions fill in what the code does not say; examples replace the
+// ones generated from types, since hand-written ones are more realistic.
+func (s *Spec) apply(e *scan.Endpoint, so *specOperation) {
+	if summary, _ := so.op["summary"].(string); summary != "" && e.Desc == "" {
+		e.Desc = summary
+	}
+	if desc, _ := so.op["description"].(string); desc != "" && e.Doc == "" {
+		e.Doc = strings.TrimSpace(desc)
+	}
+
+	// Handlers registered for several methods share these slices
+	e.Query = append([]scan.QueryParam(nil), e.Query...)
+	e.RequestHeaders = append([]scan.HeaderParam(nil), e.RequestHeaders...)
+	e.Responses = append([]scan.ResponseExample(nil), e.Responses...)
+	headers := make(map[string]string, len(e.Headers))
+	for k, v := range e.Headers {
+		headers[k] = v
+	}
+	e.Headers = headers
+	pathDocs := make(map[string]scan.ParamDoc, len(e.PathParams))
+	for k, v := range e.PathParams {
+		pathDocs[k] = v
+	}
+	e.PathParams = pathDocs
+
+	s.applyParameters(e, so)
+	s.applyRequestBody(e, so)
+	s.applyResponses(e, so.op)
+}
+
+func (s *Spec) applyParameters(e *scan.Endpoint, so *specOperation) {
+	// Operation parameters override path item ones with the same name and
+	// location
+	params := make(map[string]map[string]any)
+	var order []string
+	for _, list := range [][]any{so.pathParams, anySlice(so.op["parameters"])} {
+		for _, raw := range list {
+			p := s.resolve(raw)
+			name, _ := p["name"].(string)
+			in, _ := p["in"].(string)
+			if name == "" {
+				continue
+			}
+			id := in + ":" + name
+			if _, seen := params[id]; !seen {
+				order = append(order, id)
+			}
+			params[id] = p
+		}
+	}
+
+	// Spec and code may name path parameters differently; they match by
+	// position
+	_, specPath := scan.NormalizePath(so.path)
+	_, codePath := scan.NormalizePath(e.Path)
+	rename := make(map[string]string)
+	for i, pp := range specPath {
+		if i < len(codePath) {
+			rename[pp.Name] = codePath[i].Name
+		}
+	}
+
+	for _, id := range order {
+		p := params[id]
+		name, _ := p["name"].(string)
+		desc, _ := p["description"].(string)
+		desc = strings.TrimSpace(desc)
+		example := s.parameterExample(p)
+
+		switch p["in"] {
+		case "path":
+			codeName, ok := rename[name]
+			if !ok {
+				continue
+			}
+			e.PathParams[codeName] = scan.ParamDoc{Description: desc, Example: example}
+		case "query":
+			def := ""
+			if schema := s.resolve(p["schema"]); schema != nil && schema["default"] != nil {
+				def = exampleString(schema["default"])
+			} else if p["default"] != nil {
+				def = exampleString(p["default"]) // Swagger 2.0 types parameters inline
+			}
+			found := false
+			for i := range e.Query {
+				q := &e.Query[i]
+				if q.Name != name {
+					continue
+				}
+				found = true
+				if q.Description == "" {
+					q.Description = desc
+				}
+				if q.Example == "" {
+					q.Example = example
+				}
+				if q.Default == "" {
+					q.Default = def
+				}
+			}
+			if !found {
+				e.Query = append(e.Query, scan.QueryParam{Name: name, Default: def, Example: example, Description: desc})
+			}
+		case "header", "cookie":
+			cookie := p["in"] == "cookie"
+			lower := strings.ToLower(name)
+			if !cookie && (lower == "authorization" || lower == "content-type" || lower == "accept") {
+				continue // described by security schemes and media types
+			}
+			if !cookie && example != "" && !hasHeader(e.Headers, name) {
+				e.Headers[name] = example
+			}
+			found := false
+			for i := range e.RequestHeaders {
+				h := &e.RequestHeaders[i]
+				if h.Cookie == cookie && strings.EqualFold(h.Name, name) {
+					found = true
+					if h.Description == "" {
+						h.Description = desc
+					}
+				}
+			}
+			if !found && (cookie || !hasHeader(e.Headers, name)) {
+				e.RequestHeaders = append(e.RequestHeaders, scan.HeaderParam{Name: name, Cookie: cookie, Description: desc})
+			}
+		}
+	}
+}
+
+func (s *Spec) applyRequestBody(e *scan.Endpoint, so *specOperation) {
+	var content map[string]any
+	if s.swagger2 {
+		content = s.bodyParameter(so)
+	} else if body := s.resolve(so.op["requestBody"]); body != nil {
+		content = s.resolve(body["content"])
+	}
+	contentType, media := pickMedia(content)
+	if media == nil {
+		return
+	}
+	if example, ok := s.mediaExample(media); ok {
+		e.BodyRaw = exampleText(example, contentType)
+	}
+}
+
+func (s *Spec) applyResponses(e *scan.Endpoint, op map[string]any) {
+	responses := s.resolve(op["responses"])
+	for _, code := range sortedMapKeys(responses) {
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			continue // "default" and ranges such as 2XX
+		}
+		resp := s.resolve(responses[code])
+		if resp == nil {
+			continue
+		}
+		content := s.resolve(resp["content"])
+		if s.swagger2 {
+			content = s.swagger2Content(resp["examples"], resp["schema"], s.mediaTypes(op, "produces"))
+		}
+		contentType, media := pickMedia(content)
+		if media == nil {
+			continue
+		}
+		example, ok := s.mediaExample(media)
+		if !ok {
+			continue
+		}
+		body := exampleText(example, contentType)
+
+		found := false
+		for i := range e.Responses {
+			r := &e.Responses[i]
+			if r.Status != status {
+				continue
+			}
+			found = true
+			r.Body = body
+			if r.ContentType == "" {
+				r.ContentType = contentType
+			}
+		}
+		if !found {
+			e.Responses = append(e.Responses, scan.ResponseExample{Status: status, ContentType: contentType, Body: body})
+		}
+	}
+}
+
+// bodyParameter returns the Swagger 2.0 "in: body" parameter of an
+// operation as an OpenAPI 3 content map
+func (s *Spec) bodyParameter(so *specOperation) map[string]any {
+	var body map[string]any
+	for _, list := range [][]any{so.pathParams, anySlice(so.op["parameters"])} {
+		for _, raw := range list {
+			if p := s.resolve(raw); p["in"] == "body" {
+				body = p
+			}
+		}
+	}
+	if body == nil {
+		return nil
+	}
+	return s.swagger2Content(nil, body["schema"], s.mediaTypes(so.op, "consumes"))
+}
+
+// swagger2Content converts a Swagger 2.0 examples map (media type to
+// example) and schema into an OpenAPI 3 content map
+func (s *Spec) swagger2Content(examples, schema any, mediaTypes []string) map[string]any {
+	content := make(map[string]any)
+	for mediaType, example := range anyMap(examples) {
+		content[mediaType] = map[string]any{"example": example}
+	}
+	if len(content) == 0 && schema != nil {
+		mediaType := "application/json"
+		if len(mediaTypes) > 0 {
+			mediaType = mediaTypes[0]
+		}
+		content[mediaType] = map[string]any{"schema": schema}
+	}
+	return content
+}
+
+// mediaTypes returns Swagger 2.0's consumes or produces list of an
+// operation, which overrides the document's
+func (s *Spec) mediaTypes(op map[string]any, key string) []string {
+	list := anySlice(op[key])
+	if list == nil {
+		list = anySlice(s.root[key])
+	}
+	var types []string
+	for _, v := range list {
+		if t, ok := v.(string); ok {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// pickMedia prefers JSON among the media types of a content map
+func pickMedia(content map[string]any) (string, map[string]any) {
+	if content == nil {
+		return "", nil
+	}
+	keys := sortedMapKeys(content)
+	for _, k := range keys {
+		if k == "application/json" || strings.HasSuffix(k, "+json") {
+			return k, anyMap(content[k])
+		}
+	}
+	if len(keys) == 0 {
+		return "", nil
+	}
+	return keys[0], anyMap(content[keys[0]])
+}
+
+// mediaExample returns the example of a media type: example, the first
+// of examples (by name) or the schema's example
+func (s *Spec) mediaExample(media map[string]any) (any, bool) {
+	if v, ok := media["example"]; ok {
+		return v, true
+	}
+	if v, ok := s.namedExample(media["examples"]); ok {
+		return v, true
+	}
+	if schema := s.resolve(media["schema"]); schema != nil {
+		if v, ok := schema["example"]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// parameterExample returns a parameter's example as a string
+func (s *Spec) parameterExample(p map[string]any) string {
+	if v, ok := s.mediaExample(p); ok {
+		return exampleString(v)
+	}
+	return ""
+}
+
+func (s *Spec) namedExample(raw any) (any, bool) {
+	examples := anyMap(raw)
+	for _, name := range sortedMapKeys(examples) {
+		if ex := s.resolve(examples[name]); ex != nil {
+			if v, ok := ex["value"]; ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// resolve follows local $refs (#/components/...) and returns the mapping
+// they point to, or nil
+func (s *Spec) resolve(v any) map[string]any {
+	for hops := 0; hops < 16; hops++ {
+		m := anyMap(v)
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return m
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil // external references are not followed
+		}
+		var target any = s.root
+		for _, part := range strings.Split(ref[2:], "/") {
+			part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+			target = anyMap(target)[part]
+		}
+		v = target
+	}
+	return nil
+}
+
+// exampleText renders an example as a body: JSON for JSON media types,
+// strings as they are otherwise
+func exampleText(v any, contentType string) string {
+	str, isString := v.(string)
+	isJSON := contentType == "" || contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+	if isString && (!isJSON || isJSONDocument(str)) {
+		return str
+	}
+	return exampleString(v)
+}
+
+func isJSONDocument(s string) bool {
+	s = strings.TrimSpace(s)
+	return (strings.HasPrefix(s, "{") || strings.HasPrefix(s, "[")) && json.Valid([]byte(s))
+}
+
+// exampleString renders a scalar example as text and anything else as
+// compact JSON
+func exampleString(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(k, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func anySlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+func sortedMapKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
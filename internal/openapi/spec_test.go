@@ -0,0 +1,266 @@
+package openapi
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	src := `
+# comment
+title: Users API   # trailing comment
+version: "1.0"
+count: 007
+ratio: 1.50
+enabled: true
+missing: ~
+url: https://api.example.com/v1
+quoted: 'it''s'
+escaped: "tab\there é"
+plain: first line
+  second line
+literal: |
+  line one
+    indented
+folded: >-
+  folded
+  text
+
+  new paragraph
+list:
+- a
+- b: 1
+  c: 2
+-
+  - nested
+flow: {name: Ada, tags: [x, "y, z"], "n": 1}
+multi: [
+  1,
+  2
+]
+empty: []
+`
+	got, err := decodeYAML([]byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"title":   "Users API",
+		"version": "1.0",
+		"count":   json.Number("7"),
+		"ratio":   json.Number("1.5"),
+		"enabled": true,
+		"missing": nil,
+		"url":     "https://api.example.com/v1",
+		"quoted":  "it's",
+		"escaped": "tab\there é",
+		"plain":   "first line second line",
+		"literal": "line one\n  indented\n",
+		"folded":  "folded text\nnew paragraph",
+		"list": []any{
+			"a",
+			map[string]any{"b": json.Number("1"), "c": json.Number("2")},
+			[]any{"nested"},
+		},
+		"flow":  map[string]any{"name": "Ada", "tags": []any{"x", "y, z"}, "n": json.Number("1")},
+		"multi": []any{json.Number("1"), json.Number("2")},
+		"empty": []any{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.MarshalIndent(got, "", "  ")
+		t.Errorf("decodeYAML =\n%s", gotJSON)
+	}
+
+	got, err = decodeYAML([]byte(`
+base: &base {type: string, example: x}
+id: *base
+merged:
+  <<: *base
+  example: y
+tagged: !!str 12
+hex: 0x1F
+big: 1_000
+inf: .inf
+200: OK
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := got.(map[string]any)
+	for key, want := range map[string]any{
+		"id":     map[string]any{"type": "string", "example": "x"},
+		"merged": map[string]any{"type": "string", "example": "y"},
+		"tagged": "12",
+		"hex":    json.Number("31"),
+		"big":    json.Number("1000"),
+		"inf":    math.Inf(1),
+		"200":    "OK",
+	} {
+		if !reflect.DeepEqual(m[key], want) {
+			t.Errorf("%s = %#v, want %#v", key, m[key], want)
+		}
+	}
+
+	for _, src := range []string{"a: {b: 1,\n", "? [a, b]\n: 1\n", "a:\n\tb: 1\n"} {
+		if _, err := decodeYAML([]byte(src)); err == nil {
+			t.Errorf("expected an error for %q", src)
+		}
+	}
+}
+
+const testSpec = `
+openapi: 3.0.3
+servers:
+  - url: https://api.example.com/v1
+paths:
+  /users/{userId}:
+    parameters:
+      - $ref: '#/components/parameters/UserId'
+    get:
+      summary: Get a user
+      parameters:
+        - name: fields
+          in: query
+          description: Fields to return
+          example: id,name
+        - name: X-Request-ID
+          in: header
+          schema: {type: string, example: req-1}
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              examples:
+                ada:
+                  $ref: '#/components/examples/Ada'
+        '404':
+          description: Not found
+          content:
+            application/json:
+              example: {error: not found}
+  /users:
+    post:
+      summary: Create a user
+      requestBody:
+        content:
+          application/json:
+            example:
+              name: Ada
+  /legacy:
+    get:
+      summary: Old
+components:
+  parameters:
+    UserId:
+      name: userId
+      in: path
+      description: The user's id
+      example: 42
+  examples:
+    Ada:
+      value: {id: 42, name: Ada}
+`
+
+func TestSpecEnrich(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := []scan.ResponseExample{{Status: 200, ContentType: "application/json", Body: `{"id":0,"name":"string"}`}}
+	eps := []scan.Endpoint{
+		{Method: "GET", Path: "/v1/users/{id}", Desc: "", Query: []scan.QueryParam{{Name: "fields"}}, Responses: responses},
+		{Method: "ANY", Path: "/v1/users/", BodyRaw: `{"name":"string"}`, Desc: "Users"},
+		{Method: "GET", Path: "/v1/health", Responses: responses},
+	}
+	report := spec.Enrich(eps)
+
+	if report.Matched != 2 || !reflect.DeepEqual(report.SpecOnly, []string{"GET /v1/legacy"}) || !reflect.DeepEqual(report.CodeOnly, []string{"GET /v1/health"}) {
+		t.Errorf("report = %+v", report)
+	}
+
+	get := eps[0]
+	if get.Desc != "Get a user" {
+		t.Errorf("Desc = %q", get.Desc)
+	}
+	if get.PathParams["id"] != (scan.ParamDoc{Description: "The user's id", Example: "42"}) {
+		t.Errorf("PathParams = %+v", get.PathParams)
+	}
+	if get.Query[0] != (scan.QueryParam{Name: "fields", Example: "id,name", Description: "Fields to return"}) {
+		t.Errorf("Query = %+v", get.Query)
+	}
+	if get.Headers["X-Request-ID"] != "req-1" {
+		t.Errorf("Headers = %+v", get.Headers)
+	}
+	if len(get.Responses) != 2 || get.Responses[0].Body != `{"id":42,"name":"Ada"}` ||
+		get.Responses[1] != (scan.ResponseExample{Status: 404, ContentType: "application/json", Body: `{"error":"not found"}`}) {
+		t.Errorf("Responses = %+v", get.Responses)
+	}
+	// the detected response shared with /v1/health is left alone
+	if responses[0].Body != `{"id":0,"name":"string"}` {
+		t.Errorf("shared response changed: %+v", responses[0])
+	}
+
+	post := eps[1]
+	if post.Desc != "Users" || post.BodyRaw != `{"name":"Ada"}` {
+		t.Errorf("ANY /v1/users/ = %q, %q", post.Desc, post.BodyRaw)
+	}
+}
+
+const testSwagger2 = `
+swagger: "2.0"
+basePath: /v1
+produces: [application/json]
+paths:
+  /users:
+    post:
+      summary: Create a user
+      parameters:
+        - name: body
+          in: body
+          schema: {$ref: '#/definitions/User'}
+        - name: dry_run
+          in: query
+          type: boolean
+          default: false
+      responses:
+        201:
+          description: Created
+          examples:
+            application/json: {id: 1, name: Ada}
+definitions:
+  User:
+    type: object
+    example: {name: Ada}
+`
+
+func TestSpecEnrich_Swagger2(t *testing.T) {
+	spec, err := ParseSpec([]byte(testSwagger2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	eps := []scan.Endpoint{{Method: "POST", Path: "/v1/users", BodyRaw: `{"name":"string"}`}}
+	report := spec.Enrich(eps)
+	if report.Matched != 1 || len(report.SpecOnly) != 0 || len(report.CodeOnly) != 0 {
+		t.Errorf("report = %+v", report)
+	}
+
+	e := eps[0]
+	if e.Desc != "Create a user" || e.BodyRaw != `{"name":"Ada"}` {
+		t.Errorf("Desc = %q, BodyRaw = %q", e.Desc, e.BodyRaw)
+	}
+	if len(e.Query) != 1 || e.Query[0] != (scan.QueryParam{Name: "dry_run", Default: "false"}) {
+		t.Errorf("Query = %+v", e.Query)
+	}
+	if len(e.Responses) != 1 || e.Responses[0] != (scan.ResponseExample{Status: 201, ContentType: "application/json", Body: `{"id":1,"name":"Ada"}`}) {
+		t.Errorf("Responses = %+v", e.Responses)
+	}
+
+	if _, err := ParseSpec([]byte("swagger: \"1.2\"\npaths: {}\n")); err == nil {
+		t.Error("expected an error for Swagger 1.2")
+	}
+}
@@ -0,0 +1,178 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MarshalIndent renders the document as indented JSON
+func (d *Document) MarshalIndent() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
+
+// MarshalYAML renders the document as YAML, keeping the key order of the
+// JSON encoding
+func (d *Document) MarshalYAML() ([]byte, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	// JSON is YAML: decoding it into a node keeps the key order
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	blockStyle(&root)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle drops the flow and quoting styles of decoded JSON; the
+// encoder quotes strings that would read as another type in YAML 1.2, and
+// YAML 1.1 booleans stay quoted for older readers
+func blockStyle(n *yaml.Node) {
+	n.Style = 0
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!str" && yaml11Bools[strings.ToLower(n.Value)] {
+		n.Style = yaml.DoubleQuotedStyle
+	}
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
+
+var yaml11Bools = map[string]bool{"y": true, "yes": true, "n": true, "no": true, "on": true, "off": true}
+
+// maxYAMLNodes bounds the nodes a document may expand to through aliases
+const maxYAMLNodes = 1 << 20
+
+// decodeYAML reads a YAML document into the values encoding/json decodes
+// with UseNumber: map[string]any, []any, string, json.Number, bool and nil
+func decodeYAML(data []byte) (any, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	budget := maxYAMLNodes
+	return yamlValue(&root, &budget)
+}
+
+func yamlValue(n *yaml.Node, budget *int) (any, error) {
+	if *budget--; *budget < 0 {
+		return nil, fmt.Errorf("yaml: document expands to more than %d nodes", maxYAMLNodes)
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		if len(n.Content) == 0 {
+			return nil, nil
+		}
+		return yamlValue(n.Content[0], budget)
+	case yaml.AliasNode:
+		return yamlValue(n.Alias, budget)
+	case yaml.SequenceNode:
+		items := make([]any, 0, len(n.Content))
+		for _, c := range n.Content {
+			v, err := yamlValue(c, budget)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+		return items, nil
+	case yaml.MappingNode:
+		return yamlMapping(n, budget)
+	case yaml.ScalarNode:
+		return yamlScalar(n)
+	}
+	return nil, fmt.Errorf("yaml: line %d: unexpected node", n.Line)
+}
+
+// yamlMapping decodes a mapping; merge keys (<<) are applied first so the
+// mapping's own keys override them
+func yamlMapping(n *yaml.Node, budget *int) (map[string]any, error) {
+	m := make(map[string]any, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if key := n.Content[i]; key.Kind != yaml.ScalarNode || key.ShortTag() != "!!merge" {
+			continue
+		}
+		merged, err := yamlValue(n.Content[i+1], budget)
+		if err != nil {
+			return nil, err
+		}
+		sources := []any{merged}
+		if list, ok := merged.([]any); ok {
+			sources = list
+		}
+		for _, src := range sources {
+			fields, ok := src.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("yaml: line %d: merge value is not a mapping", n.Content[i].Line)
+			}
+			for k, v := range fields {
+				if _, exists := m[k]; !exists {
+					m[k] = v
+				}
+			}
+		}
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if key.Kind == yaml.AliasNode {
+			key = key.Alias
+		}
+		if key.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("yaml: line %d: unsupported mapping key", key.Line)
+		}
+		if key.ShortTag() == "!!merge" {
+			continue
+		}
+		v, err := yamlValue(n.Content[i+1], budget)
+		if err != nil {
+			return nil, err
+		}
+		m[key.Value] = v
+	}
+	return m, nil
+}
+
+// yamlScalar resolves a scalar by its tag; timestamps keep their text
+func yamlScalar(n *yaml.Node) (any, error) {
+	switch n.ShortTag() {
+	case "!!null":
+		return nil, nil
+	case "!!bool":
+		var b bool
+		err := n.Decode(&b)
+		return b, err
+	case "!!int":
+		var v any
+		if err := n.Decode(&v); err != nil {
+			return nil, err
+		}
+		return json.Number(fmt.Sprint(v)), nil
+	case "!!float":
+		var f float64
+		if err := n.Decode(&f); err != nil {
+			return nil, err
+		}
+		if math.IsInf(f, 0) || math.IsNaN(f) {
+			return f, nil // no JSON number for it
+		}
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64)), nil
+	}
+	return n.Value, nil
+}
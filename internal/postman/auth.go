@@ -0,0 +1,233 @@
+package postman
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/williamkoller/postman-gen/internal/scan"
+)
+
+// Auth is a Postman auth object. The attributes of the active type are
+// listed under the key named after it; "noauth" has none and stops a
+// request or folder from inheriting its parent's auth.
+type Auth struct {
+	Type   string          `json:"type"`
+	Bearer []AuthAttribute `json:"bearer,omitempty"`
+	Basic  []AuthAttribute `json:"basic,omitempty"`
+	Digest []AuthAttribute `json:"digest,omitempty"`
+	APIKey []AuthAttribute `json:"apikey,omitempty"`
+	OAuth2 []AuthAttribute `json:"oauth2,omitempty"`
+}
+
+type AuthAttribute struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type"`
+}
+
+var noAuth = &Auth{Type: "noauth"}
+
+// authFromInfo maps scanned or configured auth to a Postman auth object.
+// Credentials are always environment variables, by default {{token}},
+// {{username}}/{{password}}, {{apiKey}} and {{accessToken}}.
+func authFromInfo(a *scan.AuthInfo) *Auth {
+	if a == nil {
+		return nil
+	}
+	cred := func(i int, def string) string {
+		if i < len(a.Credentials) {
+			return a.Credentials[i]
+		}
+		return def
+	}
+	attr := func(key, value string) AuthAttribute {
+		return AuthAttribute{Key: key, Value: value, Type: "string"}
+	}
+
+	switch a.Type {
+	case "noauth":
+		return &Auth{Type: "noauth"}
+	case "bearer":
+		return &Auth{Type: "bearer", Bearer: []AuthAttribute{
+			attr("token", cred(0, "{{token}}")),
+		}}
+	case "basic":
+		return &Auth{Type: "basic", Basic: []AuthAttribute{
+			attr("username", cred(0, "{{username}}")),
+			attr("password", cred(1, "{{password}}")),
+		}}
+	case "digest":
+		return &Auth{Type: "digest", Digest: []AuthAttribute{
+			attr("username", cred(0, "{{username}}")),
+			attr("password", cred(1, "{{password}}")),
+		}}
+	case "apikey":
+		key := a.Key
+		if key == "" {
+			key = "X-API-Key"
+		}
+		return &Auth{Type: "apikey", APIKey: []AuthAttribute{
+			attr("key", key),
+			attr("value", cred(0, "{{apiKey}}")),
+			attr("in", "header"),
+		}}
+	case "oauth2":
+		return &Auth{Type: "oauth2", OAuth2: []AuthAttribute{
+			attr("accessToken", cred(0, "{{accessToken}}")),
+			attr("tokenType", "Bearer"),
+			attr("addTokenTo", "header"),
+		}}
+	}
+	return nil
+}
+
+// Attrs returns the attributes of the active auth type by key
+func (a *Auth) Attrs() map[string]string {
+	if a == nil {
+		return nil
+	}
+	var list []AuthAttribute
+	switch a.Type {
+	case "bearer":
+		list = a.Bearer
+	case "basic":
+		list = a.Basic
+	case "digest":
+		list = a.Digest
+	case "apikey":
+		list = a.APIKey
+	case "oauth2":
+		list = a.OAuth2
+	}
+	m := make(map[string]string, len(list))
+	for _, attr := range list {
+		m[attr.Key] = attr.Value
+	}
+	return m
+}
+
+// ResolveAuth returns the auth of a request or folder: its own, or the
+// one it inherits from its parent
+func (it Item) ResolveAuth(inherited *Auth) *Auth {
+	own := it.Auth
+	if it.Request != nil {
+		own = it.Request.Auth
+	}
+	if own != nil {
+		return own
+	}
+	return inherited
+}
+
+// Walk calls fn for every request below items, in order, with the auth
+// the request inherits from its folders resolved; clients without auth
+// inheritance send it on every request
+func Walk(items []Item, inherited *Auth, fn func(it Item, auth *Auth)) {
+	for _, it := range items {
+		auth := it.ResolveAuth(inherited)
+		if it.Request == nil {
+			Walk(it.Item, auth, fn)
+			continue
+		}
+		fn(it, auth)
+	}
+}
+
+// effectiveAuth treats a missing collection auth as noauth
+func effectiveAuth(a *Auth) *Auth {
+	if a == nil {
+		return noAuth
+	}
+	return a
+}
+
+// liftAuth sets a folder's auth when every request below it shares the
+// same auth, and returns the auth shared by all of items (nil if none)
+func liftAuth(items []Item) *Auth {
+	var common *Auth
+	shared := len(items) > 0
+	for i := range items {
+		it := &items[i]
+		var a *Auth
+		if it.Request != nil {
+			a = it.Request.Auth
+		} else {
+			it.Auth = liftAuth(it.Item)
+			a = it.Auth
+		}
+		// a nil auth inherits, so it can't be shared with siblings
+		switch {
+		case a == nil:
+			shared = false
+		case common == nil:
+			common = a
+		case !reflect.DeepEqual(common, a):
+			shared = false
+		}
+	}
+	if !shared {
+		return nil
+	}
+	return common
+}
+
+// dropInheritedAuth removes auth identical to what the parent provides, so
+// requests and folders fall back to Postman's "inherit auth from parent"
+func dropInheritedAuth(items []Item, inherited *Auth) {
+	for i := range items {
+		it := &items[i]
+		if it.Request != nil {
+			if reflect.DeepEqual(it.Request.Auth, inherited) {
+				it.Request.Auth = nil
+			}
+			continue
+		}
+		own := it.Auth
+		if own == nil {
+			own = inherited
+		} else if reflect.DeepEqual(own, inherited) {
+			it.Auth = nil
+		}
+		dropInheritedAuth(it.Item, own)
+	}
+}
+
+var authVariableRe = regexp.MustCompile(`\{\{([^{}]+)\}\}`)
+
+// AuthVariables lists the environment variables the collection's auth
+// objects reference, so they can be added to the environment
+func AuthVariables(col Collection) []string {
+	seen := make(map[string]bool)
+	addAuth := func(a *Auth) {
+		if a == nil {
+			return
+		}
+		for _, attrs := range [][]AuthAttribute{a.Bearer, a.Basic, a.Digest, a.APIKey, a.OAuth2} {
+			for _, attr := range attrs {
+				for _, m := range authVariableRe.FindAllStringSubmatch(attr.Value, -1) {
+					seen[m[1]] = true
+				}
+			}
+		}
+	}
+	var walk func(items []Item)
+	walk = func(items []Item) {
+		for _, it := range items {
+			addAuth(it.Auth)
+			if it.Request != nil {
+				addAuth(it.Request.Auth)
+			}
+			walk(it.Item)
+		}
+	}
+	addAuth(col.Auth)
+	walk(col.Item)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -1,6 +1,10 @@
 package postman
 
-import "time"
+import (
+	"strings"
+	"time"
+	"unicode"
+)
 
 type Environment struct {
 	ID                   string     `json:"id"`
@@ -18,15 +22,51 @@ type EnvValue struct {
 	Enabled bool   `json:"enabled"`
 }
 
-func BuildEnvironment(name, baseURL string) Environment {
+// BuildEnvironment creates an environment with baseUrl and an empty secret
+// value for each credential variable (see AuthVariables)
+func BuildEnvironment(name, baseURL string, secrets ...string) Environment {
+	values := []EnvValue{
+		{Key: "baseUrl", Value: baseURL, Type: "text", Enabled: true},
+	}
+	for _, key := range secrets {
+		values = append(values, EnvValue{Key: key, Value: "", Type: "secret", Enabled: true})
+	}
 	return Environment{
-		ID:   uuidV4(),
-		Name: name,
-		Values: []EnvValue{
-			{Key: "baseUrl", Value: baseURL, Type: "text", Enabled: true},
-		},
+		ID:                   uuidV4(),
+		Name:                 name,
+		Values:               values,
 		PostmanVariableScope: "environment",
 		PostmanExportedAt:    time.Now().Format(time.RFC3339),
 		PostmanExportedUsing: "postman-gen",
 	}
 }
+
+// CollectionEnvironment builds the environment of col: baseUrl, the
+// collection's other variables to fill in, and its credential variables
+func CollectionEnvironment(name, baseURL string, col Collection) Environment {
+	env := BuildEnvironment(name, baseURL, AuthVariables(col)...)
+	var vars []EnvValue
+	for _, v := range col.Variable {
+		if v.Key != "baseUrl" {
+			vars = append(vars, EnvValue{Key: v.Key, Value: v.Value, Type: "text", Enabled: true})
+		}
+	}
+	env.Values = append(env.Values[:1], append(vars, env.Values[1:]...)...)
+	return env
+}
+
+// EnvName maps a variable such as tenantId to the process environment
+// variable scripts read it from, TENANT_ID
+func EnvName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) && i > 0 {
+			b.WriteByte('_')
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			r = '_'
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
@@ -1,9 +1,13 @@
 package postman
 
 import (
+	"bytes"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
 	"sort"
 	"strings"
 
@@ -15,14 +19,16 @@ const schemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collecti
 type BuildOpts struct {
 	Name          string
 	BaseURL       string
-	GroupDepth    int  // 0 = plano
-	GroupByMethod bool // cria subpastas GET/POST/...
-	TagFolders    bool // cria árvore "By Tag"
+	GroupDepth    int            // 0 = plano
+	GroupByMethod bool           // cria subpastas GET/POST/...
+	TagFolders    bool           // cria árvore "By Tag"
+	Auth          *scan.AuthInfo // auth padrão da coleção (opcional)
 }
 
 type Collection struct {
 	Info     Info       `json:"info"`
 	Item     []Item     `json:"item"`
+	Auth     *Auth      `json:"auth,omitempty"`
 	Variable []Variable `json:"variable,omitempty"`
 }
 
@@ -34,14 +40,27 @@ type Info struct {
 }
 
 type Item struct {
-	Name     string   `json:"name"`
-	Request  *Request `json:"request,omitempty"`
-	Response []any    `json:"response,omitempty"`
-	Item     []Item   `json:"item,omitempty"`
+	Name     string     `json:"name"`
+	Request  *Request   `json:"request,omitempty"`
+	Auth     *Auth      `json:"auth,omitempty"`
+	Response []Response `json:"response,omitempty"`
+	Item     []Item     `json:"item,omitempty"`
+}
+
+// Response is a saved example response
+type Response struct {
+	Name            string   `json:"name"`
+	OriginalRequest *Request `json:"originalRequest,omitempty"`
+	Status          string   `json:"status,omitempty"`
+	Code            int      `json:"code,omitempty"`
+	PreviewLanguage string   `json:"_postman_previewlanguage,omitempty"`
+	Header          []Header `json:"header"`
+	Body            string   `json:"body,omitempty"`
 }
 
 type Request struct {
 	Method      string   `json:"method"`
+	Auth        *Auth    `json:"auth,omitempty"`
 	Header      []Header `json:"header"`
 	Body        *Body    `json:"body,omitempty"`
 	URL         URL      `json:"url"`
@@ -62,22 +81,77 @@ type Body struct {
 }
 
 type URL struct {
-	Raw   string   `json:"raw"`
-	Host  []string `json:"host"`
-	Path  []string `json:"path"`
-	Query []Query  `json:"query,omitempty"`
+	Raw      string     `json:"raw"`
+	Host     []string   `json:"host"`
+	Path     []string   `json:"path"`
+	Query    []Query    `json:"query,omitempty"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+var (
+	pathVariableRe = regexp.MustCompile(`(^|/):([^/]+)`)
+	nonIdentRe     = regexp.MustCompile(`[^A-Za-z0-9]+`)
+	placeholderRe  = regexp.MustCompile(`\{\{\s*([^{}\s]+)\s*\}\}`)
+)
+
+// Expand returns the URL without its query, with path variables filled
+// with their example values or left as {{name}} references, for clients
+// that don't support :name segments
+func (u URL) Expand() string {
+	values := make(map[string]string, len(u.Variable))
+	for _, v := range u.Variable {
+		values[v.Key] = v.Value
+	}
+	return ExpandPath(strings.SplitN(u.Raw, "?", 2)[0], func(name string) string {
+		if v := values[name]; v != "" {
+			return v
+		}
+		return "{{" + name + "}}"
+	})
+}
+
+// PathVariables lists the names of the :name segments of path
+func PathVariables(path string) []string {
+	var names []string
+	for _, m := range pathVariableRe.FindAllStringSubmatch(path, -1) {
+		names = append(names, m[2])
+	}
+	return names
+}
+
+// ExpandPath replaces every :name segment of path with fill(name)
+func ExpandPath(path string, fill func(name string) string) string {
+	return pathVariableRe.ReplaceAllStringFunc(path, func(seg string) string {
+		m := pathVariableRe.FindStringSubmatch(seg)
+		return m[1] + fill(m[2])
+	})
+}
+
+// RouteWords splits a request into the words of generated identifiers:
+// the lower-case method and the alphanumeric runs of the path, e.g. get,
+// v1, users, id for GET {{baseUrl}}/v1/users/:id
+func RouteWords(method, rawURL string) []string {
+	words := []string{strings.ToLower(method)}
+	for _, part := range nonIdentRe.Split(strings.TrimPrefix(rawURL, "{{baseUrl}}"), -1) {
+		if part != "" {
+			words = append(words, part)
+		}
+	}
+	return words
 }
 
 type Query struct {
 	Key         string `json:"key"`
 	Value       string `json:"value,omitempty"`
 	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
 }
 
 type Variable struct {
-	Key   string `json:"key"`
-	Value string `json:"value"`
-	Type  string `json:"type,omitempty"`
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
 }
 
 func BuildCollection(opts BuildOpts, eps []scan.Endpoint) Collection {
@@ -125,43 +199,169 @@ func BuildCollection(opts BuildOpts, eps []scan.Endpoint) Collection {
 		}
 	}
 
+	// Shared auth moves up to folders; whatever equals the inherited auth
+	// is dropped so requests keep inheriting from their folder
+	collectionAuth := authFromInfo(opts.Auth)
+	liftAuth(mainTree)
+	dropInheritedAuth(mainTree, effectiveAuth(collectionAuth))
+
 	return Collection{
 		Info: Info{
 			Name:      opts.Name,
 			PostmanID: uuidV4(),
 			Schema:    schemaV21,
 		},
-		Item: mainTree,
-		Variable: []Variable{
-			{Key: "baseUrl", Value: opts.BaseURL, Type: "string"},
-		},
+		Item:     mainTree,
+		Auth:     collectionAuth,
+		Variable: append([]Variable{{Key: "baseUrl", Value: opts.BaseURL, Type: "string"}}, placeholderVariables(eps)...),
 	}
 }
 
+// placeholderVariables declares the {{name}} path segments the scanner
+// could not resolve (a prefix computed at runtime), so they show up as
+// variables to fill in instead of unresolved references
+func placeholderVariables(eps []scan.Endpoint) []Variable {
+	seen := make(map[string]bool)
+	var vars []Variable
+	for _, e := range eps {
+		for _, m := range placeholderRe.FindAllStringSubmatch(e.Path, -1) {
+			if name := m[1]; !seen[name] && name != "baseUrl" {
+				seen[name] = true
+				vars = append(vars, Variable{Key: name, Type: "string", Description: "Path segment the scanner could not resolve"})
+			}
+		}
+	}
+	sort.Slice(vars, func(i, j int) bool { return vars[i].Key < vars[j].Key })
+	return vars
+}
+
 func buildLeafItem(baseURL string, e scan.Endpoint) Item {
 	title := strings.TrimSpace(strings.ToUpper(e.Method) + " " + e.Path)
 	req := endpointToRequest(e)
-	return Item{Name: title, Request: &req, Response: []any{}}
+	return Item{Name: title, Request: &req, Response: endpointResponses(e, req)}
+}
+
+// endpointResponses turns the responses a handler writes into saved
+// examples, one per status code and body shape
+func endpointResponses(e scan.Endpoint, req Request) []Response {
+	responses := []Response{}
+	for _, r := range e.Responses {
+		original := req
+		resp := Response{
+			Name:            r.Name(),
+			OriginalRequest: &original,
+			Status:          http.StatusText(r.Status),
+			Code:            r.Status,
+			Header:          []Header{},
+			Body:            r.Body,
+		}
+		if r.ContentType != "" {
+			resp.Header = append(resp.Header, Header{Key: "Content-Type", Value: r.ContentType})
+		}
+		switch r.ContentType {
+		case "application/json":
+			resp.PreviewLanguage = "json"
+			var pretty bytes.Buffer
+			if json.Indent(&pretty, []byte(r.Body), "", "  ") == nil {
+				resp.Body = pretty.String()
+			}
+		case "text/plain":
+			resp.PreviewLanguage = "text"
+		}
+		responses = append(responses, resp)
+	}
+	return responses
 }
 
-func pathToURL(path string) URL {
+func pathToURL(path string, query []scan.QueryParam, docs map[string]scan.ParamDoc) URL {
+	// Every router dialect ({id}, {id:[0-9]+}, *path, {rest...}) becomes :id
+	path, params := scan.NormalizePath(path)
 	raw := "{{baseUrl}}" + cleanPath(path)
 	host := []string{"{{baseUrl}}"}
 	pathSegments := splitPath(path)
 
+	// Detected query parameters are optional as far as we know, so they
+	// are listed disabled and only enabled by the user when needed
+	var queries []Query
+	var pairs []string
+	for _, q := range query {
+		value := q.Default
+		if value == "" {
+			value = q.Example
+		}
+		desc := q.Description
+		if desc == "" {
+			desc = "Detected query parameter"
+		}
+		queries = append(queries, Query{
+			Key:         q.Name,
+			Value:       value,
+			Description: desc,
+			Disabled:    !q.Required,
+		})
+		pairs = append(pairs, queryEscape(q.Name)+"="+queryEscape(value))
+	}
+	if len(pairs) > 0 {
+		raw += "?" + strings.Join(pairs, "&")
+	}
+
+	var vars []Variable
+	for _, pp := range params {
+		v := Variable{
+			Key:         pp.Name,
+			Value:       pp.ExampleValue(),
+			Description: pp.Description(),
+		}
+		if doc, ok := docs[pp.Name]; ok {
+			if doc.Example != "" {
+				v.Value = doc.Example
+			}
+			if doc.Description != "" {
+				v.Description = doc.Description
+			}
+		}
+		vars = append(vars, v)
+	}
+
 	return URL{
-		Raw:  raw,
-		Host: host,
-		Path: pathSegments,
+		Raw:      raw,
+		Host:     host,
+		Path:     pathSegments,
+		Query:    queries,
+		Variable: vars,
 	}
 }
 
+// queryEscape escapes s for the query of URL.Raw, leaving {{variable}}
+// references for Postman to substitute
+func queryEscape(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, loc := range placeholderRe.FindAllStringIndex(s, -1) {
+		b.WriteString(url.QueryEscape(s[last:loc[0]]))
+		b.WriteString(s[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	b.WriteString(url.QueryEscape(s[last:]))
+	return b.String()
+}
+
 func endpointToRequest(e scan.Endpoint) Request {
 	headers := []Header{}
 	for k, v := range e.Headers {
 		headers = append(headers, Header{Key: k, Value: v})
 	}
 
+	// Host-scoped routes (net/http "example.com/path") only match that host
+	if e.Host != "" {
+		if _, ok := e.Headers["Host"]; !ok {
+			headers = append(headers, Header{Key: "Host", Value: e.Host})
+		}
+	}
+
+	// Headers and cookies read by the handler; annotated headers win
+	headers = append(headers, detectedHeaders(e)...)
+
 	// Set default Content-Type based on endpoint type
 	hasContentType := false
 	for _, h := range headers {
@@ -234,6 +434,12 @@ func endpointToRequest(e scan.Endpoint) Request {
 		if e.Handler != "" {
 			desc += " | Handler: " + e.Handler
 		}
+		if e.Host != "" {
+			desc += " | Host: " + e.Host
+		}
+		if len(e.Middleware) > 0 {
+			desc += " | Middleware: " + strings.Join(e.Middleware, ", ")
+		}
 		if e.Type != "" {
 			desc += " | Type: " + e.Type
 		}
@@ -244,9 +450,10 @@ func endpointToRequest(e scan.Endpoint) Request {
 
 	return Request{
 		Method:      e.Method,
+		Auth:        authFromInfo(e.Auth),
 		Header:      headers,
 		Body:        body,
-		URL:         pathToURL(e.Path),
+		URL:         pathToURL(e.Path, e.Query, e.PathParams),
 		Description: desc,
 	}
 }
@@ -398,3 +605,65 @@ func uuidV4() string {
 	hexs := hex.EncodeToString(b[:])
 	return hexs[0:8] + "-" + hexs[8:12] + "-" + hexs[12:16] + "-" + hexs[16:20] + "-" + hexs[20:]
 }
+
+// detectedHeaders turns the headers and cookies a handler reads into
+// {{variable}} headers, skipping any already set by @header. Cookies are
+// combined into a single Cookie header.
+func detectedHeaders(e scan.Endpoint) []Header {
+	annotated := make(map[string]bool)
+	for k := range e.Headers {
+		annotated[strings.ToLower(k)] = true
+	}
+
+	var headers []Header
+	var cookies []string
+	for _, h := range e.RequestHeaders {
+		if h.Cookie {
+			cookies = append(cookies, h.Name+"={{"+headerVariable(h.Name)+"}}")
+			continue
+		}
+		if annotated[strings.ToLower(h.Name)] {
+			continue
+		}
+		desc := h.Description
+		if desc == "" {
+			desc = "Detected in handler code"
+		}
+		headers = append(headers, Header{
+			Key:         h.Name,
+			Value:       "{{" + headerVariable(h.Name) + "}}",
+			Description: desc,
+		})
+	}
+	if len(cookies) > 0 && !annotated["cookie"] {
+		headers = append(headers, Header{
+			Key:         "Cookie",
+			Value:       strings.Join(cookies, "; "),
+			Description: "Detected in handler code",
+		})
+	}
+	return headers
+}
+
+// headerVariable names the variable for a header value: X-Tenant-ID
+// becomes tenantId, session_id becomes sessionId
+func headerVariable(name string) string {
+	if len(name) > 2 && strings.EqualFold(name[:2], "x-") {
+		name = name[2:]
+	}
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.'
+	})
+	var b strings.Builder
+	for i, w := range words {
+		w = strings.ToLower(w)
+		if i > 0 {
+			w = strings.ToUpper(w[:1]) + w[1:]
+		}
+		b.WriteString(w)
+	}
+	if b.Len() == 0 {
+		return "header"
+	}
+	return b.String()
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/williamkoller/postman-gen/internal/scan"
@@ -66,3 +68,198 @@ func TestBuildCollection_Golden(t *testing.T) {
 		t.Errorf("collection differs.\n--- got:\n%s\n--- want:\n%s", string(gotNorm), string(wantNorm))
 	}
 }
+
+func TestBuildCollection_AuthInheritance(t *testing.T) {
+	basic := &scan.AuthInfo{Type: "basic"}
+	eps := []scan.Endpoint{
+		{Method: "GET", Path: "/admin/stats", SourceFile: "a.go", Auth: basic},
+		{Method: "POST", Path: "/admin/users", SourceFile: "a.go", Auth: basic},
+		{Method: "GET", Path: "/orders", SourceFile: "a.go"},
+		{Method: "GET", Path: "/health", SourceFile: "a.go", Auth: &scan.AuthInfo{Type: "noauth"}},
+	}
+	col := BuildCollection(BuildOpts{
+		Name:       "Auth",
+		BaseURL:    "http://localhost:8080",
+		GroupDepth: 1,
+		Auth:       &scan.AuthInfo{Type: "bearer", Credentials: []string{"{{adminToken}}"}},
+	}, eps)
+
+	if col.Auth == nil || col.Auth.Type != "bearer" || col.Auth.Bearer[0].Value != "{{adminToken}}" {
+		t.Fatalf("expected collection bearer auth with {{adminToken}}, got %+v", col.Auth)
+	}
+
+	folders := make(map[string]Item)
+	for _, it := range col.Item {
+		folders[it.Name] = it
+	}
+	admin := folders["admin"]
+	if admin.Auth == nil || admin.Auth.Type != "basic" {
+		t.Errorf("expected basic auth lifted to the admin folder, got %+v", admin.Auth)
+	}
+	for _, leaf := range admin.Item {
+		if leaf.Request.Auth != nil {
+			t.Errorf("%s: expected auth inherited from folder, got %+v", leaf.Name, leaf.Request.Auth)
+		}
+	}
+	if orders := folders["orders"]; orders.Auth != nil || orders.Item[0].Request.Auth != nil {
+		t.Errorf("orders: expected auth inherited from the collection")
+	}
+	if health := folders["health"]; health.Auth == nil || health.Auth.Type != "noauth" {
+		t.Errorf("health: expected explicit noauth, got %+v", health.Auth)
+	}
+
+	want := []string{"adminToken", "password", "username"}
+	if got := AuthVariables(col); !reflect.DeepEqual(got, want) {
+		t.Errorf("AuthVariables: expected %v, got %v", want, got)
+	}
+
+	// Walk resolves the auth every request inherits
+	resolved := make(map[string]string)
+	Walk(col.Item, col.Auth, func(it Item, auth *Auth) {
+		resolved[it.Request.URL.Expand()] = auth.Type + " " + auth.Attrs()["username"]
+	})
+	wantAuth := map[string]string{
+		"{{baseUrl}}/admin/stats": "basic {{username}}",
+		"{{baseUrl}}/admin/users": "basic {{username}}",
+		"{{baseUrl}}/orders":      "bearer ",
+		"{{baseUrl}}/health":      "noauth ",
+	}
+	if !reflect.DeepEqual(resolved, wantAuth) {
+		t.Errorf("Walk: expected %v, got %v", wantAuth, resolved)
+	}
+}
+
+func TestURLExpand(t *testing.T) {
+	u := URL{
+		Raw:      "{{baseUrl}}/users/:id/posts/:postId?page=1",
+		Variable: []Variable{{Key: "id", Value: "42"}, {Key: "postId"}},
+	}
+	if got := u.Expand(); got != "{{baseUrl}}/users/42/posts/{{postId}}" {
+		t.Errorf("Expand = %q", got)
+	}
+	if got := PathVariables("/users/:id/posts/:postId"); !reflect.DeepEqual(got, []string{"id", "postId"}) {
+		t.Errorf("PathVariables = %v", got)
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for name, want := range map[string]string{
+		"accessToken": "ACCESS_TOKEN",
+		"baseUrl":     "BASE_URL",
+		"tenant-id":   "TENANT_ID",
+		"api.key":     "API_KEY",
+	} {
+		if got := EnvName(name); got != want {
+			t.Errorf("EnvName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestBuildCollection_PlaceholderVariables(t *testing.T) {
+	col := BuildCollection(BuildOpts{Name: "P", BaseURL: "http://localhost:8080"}, []scan.Endpoint{
+		{Method: "GET", Path: "/{{prefix}}/z", SourceFile: "a.go"},
+		{Method: "GET", Path: "/{{prefix}}/y", SourceFile: "a.go", Auth: &scan.AuthInfo{Type: "bearer"}},
+	})
+	want := []Variable{
+		{Key: "baseUrl", Value: "http://localhost:8080", Type: "string"},
+		{Key: "prefix", Type: "string", Description: "Path segment the scanner could not resolve"},
+	}
+	if !reflect.DeepEqual(col.Variable, want) {
+		t.Errorf("Variable = %+v", col.Variable)
+	}
+
+	var keys []string
+	for _, v := range CollectionEnvironment("Local", "http://localhost:8080", col).Values {
+		keys = append(keys, v.Key+":"+v.Type)
+	}
+	if wantKeys := []string{"baseUrl:text", "prefix:text", "token:secret"}; !reflect.DeepEqual(keys, wantKeys) {
+		t.Errorf("environment values = %v, want %v", keys, wantKeys)
+	}
+}
+
+func TestBuildCollection_QueryParams(t *testing.T) {
+	col := BuildCollection(BuildOpts{Name: "Q", BaseURL: "http://localhost:8080"}, []scan.Endpoint{{
+		Method: "GET", Path: "/search", SourceFile: "a.go",
+		Query: []scan.QueryParam{
+			{Name: "q", Example: "a b&c=d", Required: true},
+			{Name: "tags[]", Default: "x"},
+			{Name: "token", Example: "{{apiToken}}"},
+		},
+	}})
+	u := col.Item[0].Request.URL
+	if want := "{{baseUrl}}/search?q=a+b%26c%3Dd&tags%5B%5D=x&token={{apiToken}}"; u.Raw != want {
+		t.Errorf("Raw = %q, want %q", u.Raw, want)
+	}
+	want := []Query{
+		{Key: "q", Value: "a b&c=d", Description: "Detected query parameter"},
+		{Key: "tags[]", Value: "x", Description: "Detected query parameter", Disabled: true},
+		{Key: "token", Value: "{{apiToken}}", Description: "Detected query parameter", Disabled: true},
+	}
+	if !reflect.DeepEqual(u.Query, want) {
+		t.Errorf("Query = %+v", u.Query)
+	}
+}
+
+func TestBuildCollection_Headers(t *testing.T) {
+	col := BuildCollection(BuildOpts{Name: "H", BaseURL: "http://localhost:8080"}, []scan.Endpoint{{
+		Method: "POST", Path: "/users", SourceFile: "a.go",
+		Headers: map[string]string{"X-Req": "1"},
+		RequestHeaders: []scan.HeaderParam{
+			{Name: "X-Tenant-ID"},
+			{Name: "x-req"},
+			{Name: "session", Cookie: true},
+			{Name: "theme", Cookie: true},
+		},
+	}})
+	want := []Header{
+		{Key: "X-Req", Value: "1"},
+		{Key: "X-Tenant-ID", Value: "{{tenantId}}", Description: "Detected in handler code"},
+		{Key: "Cookie", Value: "session={{session}}; theme={{theme}}", Description: "Detected in handler code"},
+	}
+	if got := col.Item[0].Request.Header; !reflect.DeepEqual(got, want) {
+		t.Errorf("Header = %+v", got)
+	}
+}
+
+func TestBuildCollection_Responses(t *testing.T) {
+	col := BuildCollection(BuildOpts{Name: "R", BaseURL: "http://localhost:8080"}, []scan.Endpoint{{
+		Method: "GET", Path: "/orders/{id}", SourceFile: "a.go",
+		Responses: []scan.ResponseExample{
+			{Status: 200, ContentType: "application/json", Body: `{"id":0}`},
+			{Status: 404, ContentType: "text/plain", Body: "not found"},
+		},
+	}})
+	it := col.Item[0]
+	if len(it.Response) != 2 {
+		t.Fatalf("expected 2 responses, got %d", len(it.Response))
+	}
+	ok, missing := it.Response[0], it.Response[1]
+	if ok.Code != 200 || ok.Status != "OK" || ok.PreviewLanguage != "json" || ok.Body != "{\n  \"id\": 0\n}" {
+		t.Errorf("200 response = %+v", ok)
+	}
+	if missing.Code != 404 || missing.Status != "Not Found" || missing.PreviewLanguage != "text" || missing.Body != "not found" {
+		t.Errorf("404 response = %+v", missing)
+	}
+	if want := []Header{{Key: "Content-Type", Value: "text/plain"}}; !reflect.DeepEqual(missing.Header, want) {
+		t.Errorf("404 headers = %+v", missing.Header)
+	}
+	if ok.OriginalRequest == nil || ok.OriginalRequest.URL.Raw != it.Request.URL.Raw {
+		t.Errorf("expected the original request to be saved with the example")
+	}
+}
+
+func TestBuildCollection_RequestAuth(t *testing.T) {
+	col := BuildCollection(BuildOpts{Name: "A", BaseURL: "http://localhost:8080"}, []scan.Endpoint{{
+		Method: "GET", Path: "/users", SourceFile: "a.go",
+		Middleware: []string{"authMiddleware"},
+		Auth:       &scan.AuthInfo{Type: "apikey", Key: "X-Client-Key"},
+	}})
+	req := col.Item[0].Request
+	want := map[string]string{"key": "X-Client-Key", "value": "{{apiKey}}", "in": "header"}
+	if req.Auth == nil || req.Auth.Type != "apikey" || !reflect.DeepEqual(req.Auth.Attrs(), want) {
+		t.Errorf("Auth = %+v", req.Auth)
+	}
+	if !strings.Contains(req.Description, "Middleware: authMiddleware") {
+		t.Errorf("expected middleware in the description, got %q", req.Description)
+	}
+}
@@ -26,6 +26,6 @@ func FuzzAnnotations(f *testing.F) {
 		if err != nil {
 			return
 		}
-		_, _ = scanAnnotationsFromFile(file, "fuzz.go")
+		_, _ = scanAnnotationsFromFile(file, "fuzz.go", fset)
 	})
 }
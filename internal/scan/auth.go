@@ -0,0 +1,107 @@
+package scan
+
+import (
+	"fmt"
+	"go/ast"
+	"regexp"
+	"strings"
+)
+
+// AuthInfo is the authentication a route requires, from its middleware or
+// an @auth annotation
+type AuthInfo struct {
+	Type        string   // "noauth", "bearer", "basic", "digest", "apikey" or "oauth2"
+	Key         string   // header carrying the API key (apikey only)
+	Credentials []string // {{variable}} references; empty means the defaults
+}
+
+var authVariableRe = regexp.MustCompile(`^\{\{[A-Za-z_][A-Za-z0-9_.\-]*\}\}$`)
+
+// ParseAuthSpec parses the auth syntax shared by the -auth flag and the
+// @auth annotation:
+//
+//	noauth (or none)
+//	bearer[:{{token}}]
+//	oauth2[:{{accessToken}}]
+//	basic[:{{username}}:{{password}}]
+//	digest[:{{username}}:{{password}}]
+//	apikey[:Header-Name[:{{apiKey}}]]
+//
+// Credentials must be {{variable}} references so secrets live in the
+// environment, never in the collection.
+func ParseAuthSpec(spec string) (*AuthInfo, error) {
+	typ, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	var parts []string
+	if rest != "" {
+		parts = strings.Split(rest, ":")
+	}
+
+	a := &AuthInfo{Type: strings.ToLower(typ)}
+	switch a.Type {
+	case "none", "noauth":
+		a.Type = "noauth"
+		if len(parts) > 0 {
+			return nil, fmt.Errorf("auth %q: noauth takes no credentials", spec)
+		}
+		return a, nil
+	case "bearer", "oauth2":
+		if len(parts) > 1 {
+			return nil, fmt.Errorf("auth %q: expected %s:{{token}}", spec, a.Type)
+		}
+		a.Credentials = parts
+	case "basic", "digest":
+		if len(parts) != 0 && len(parts) != 2 {
+			return nil, fmt.Errorf("auth %q: expected %s:{{username}}:{{password}}", spec, a.Type)
+		}
+		a.Credentials = parts
+	case "apikey":
+		if len(parts) > 2 {
+			return nil, fmt.Errorf("auth %q: expected apikey:Header-Name:{{apiKey}}", spec)
+		}
+		a.Key = "X-API-Key"
+		if len(parts) > 0 && parts[0] != "" {
+			a.Key = parts[0]
+		}
+		if len(parts) > 1 {
+			a.Credentials = parts[1:]
+		}
+	default:
+		return nil, fmt.Errorf("auth %q: unknown type %q (want noauth, bearer, basic, digest, apikey or oauth2)", spec, typ)
+	}
+
+	for _, c := range a.Credentials {
+		if !authVariableRe.MatchString(c) {
+			return nil, fmt.Errorf("auth %q: credential %q must be a variable reference such as {{token}}", spec, c)
+		}
+	}
+	return a, nil
+}
+
+// annotationAuth parses an @auth value. A spec inlining a secret keeps its
+// scheme but falls back to the default variables rather than copying the
+// secret into the collection.
+func annotationAuth(spec string) *AuthInfo {
+	if a, err := ParseAuthSpec(spec); err == nil {
+		return a
+	}
+	typ, _, _ := strings.Cut(spec, ":")
+	if a, err := ParseAuthSpec(typ); err == nil {
+		return a
+	}
+	return nil
+}
+
+// docAuth returns the @auth annotation in a function's doc comment, which
+// applies to every route using the function as handler
+func docAuth(doc *ast.CommentGroup) *AuthInfo {
+	if doc == nil {
+		return nil
+	}
+	var auth *AuthInfo
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if m := authRe.FindStringSubmatch(strings.TrimSpace(line)); len(m) > 0 {
+			auth = annotationAuth(m[1])
+		}
+	}
+	return auth
+}
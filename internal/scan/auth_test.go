@@ -0,0 +1,90 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseAuthSpec(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    *AuthInfo
+		wantErr bool
+	}{
+		{spec: "bearer", want: &AuthInfo{Type: "bearer"}},
+		{spec: "bearer:{{token}}", want: &AuthInfo{Type: "bearer", Credentials: []string{"{{token}}"}}},
+		{spec: "none", want: &AuthInfo{Type: "noauth"}},
+		{spec: "basic:{{user}}:{{pass}}", want: &AuthInfo{Type: "basic", Credentials: []string{"{{user}}", "{{pass}}"}}},
+		{spec: "apikey:X-Key:{{key}}", want: &AuthInfo{Type: "apikey", Key: "X-Key", Credentials: []string{"{{key}}"}}},
+		{spec: "apikey", want: &AuthInfo{Type: "apikey", Key: "X-API-Key"}},
+		{spec: "oauth2:{{accessToken}}", want: &AuthInfo{Type: "oauth2", Credentials: []string{"{{accessToken}}"}}},
+		{spec: "bearer:s3cr3t", wantErr: true},
+		{spec: "basic:{{user}}", wantErr: true},
+		{spec: "hawk", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseAuthSpec(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error, got %+v", tt.spec, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: expected %+v, got %+v", tt.spec, tt.want, got)
+		}
+	}
+}
+
+func TestScanDir_AuthAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import "github.com/gin-gonic/gin"
+
+// @route GET /v1/reports
+// @auth basic:{{reportUser}}:{{reportPass}}
+func reports(c *gin.Context) {}
+
+// @auth none
+func health(c *gin.Context) {}
+
+// @auth bearer:hardcoded-secret
+func me(c *gin.Context) {}
+
+func main() {
+	r := gin.Default()
+	api := r.Group("/api", gin.BasicAuth(accounts))
+	api.GET("/health", health)
+	api.GET("/me", me)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string]*AuthInfo)
+	for _, e := range eps {
+		got[e.Method+" "+e.Path] = e.Auth
+	}
+
+	want := map[string]*AuthInfo{
+		"GET /v1/reports": {Type: "basic", Credentials: []string{"{{reportUser}}", "{{reportPass}}"}},
+		"GET /api/health": {Type: "noauth"},
+		"GET /api/me":     {Type: "bearer"}, // the inlined secret is never copied
+	}
+	for route, auth := range want {
+		if !reflect.DeepEqual(got[route], auth) {
+			t.Errorf("%s: expected %+v, got %+v", route, auth, got[route])
+		}
+	}
+}
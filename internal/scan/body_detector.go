@@ -4,12 +4,18 @@ import (
 	"fmt"
 	"go/ast"
 	"go/token"
+	"go/types"
+	"reflect"
 	"strings"
+	"unicode"
 )
 
 // Global project analysis - set by ScanDir
 var globalProjectAnalysis *ProjectAnalysis
 
+// Global type information - set by typed scans (ScanDirWithOpts)
+var globalTypesInfo *types.Info
+
 // BodyDetectionResult contains information about detected JSON bodies
 type BodyDetectionResult struct {
 	HasBody     bool
@@ -21,7 +27,9 @@ type BodyDetectionResult struct {
 type StructFieldInfo struct {
 	Name     string
 	Type     string
-	JSONTag  string
+	JSONTag  string // key encoding/json writes; "-" when skipped, "" for promoted embedded fields
+	Tag      string // raw struct tag, without backquotes
+	Embedded bool   // anonymous field; Name is its type name without package or pointer
 	Required bool
 }
 
@@ -33,6 +41,10 @@ type StructInfo struct {
 
 // DetectJSONBody analyzes a function to detect if it expects a JSON body
 func DetectJSONBody(fn *ast.FuncDecl, fset *token.FileSet) BodyDetectionResult {
+	return detectJSONBody(fn, fset, nil)
+}
+
+func detectJSONBody(fn *ast.FuncDecl, fset *token.FileSet, ex *exampleOptions) BodyDetectionResult {
 	result := BodyDetectionResult{}
 
 	if fn.Body == nil {
@@ -49,28 +61,31 @@ func DetectJSONBody(fn *ast.FuncDecl, fset *token.FileSet) BodyDetectionResult {
 			// Check for ShouldBindJSON, BindJSON, etc.
 			if checkGinJSONBinding(node) {
 				result.HasBody = true
-				result.BodyExample = generateSmartBodyExample(node, structInfo)
+				result.BodyExample = generateSmartBodyExample(node, structInfo, ex)
+				result.StructName = bodyStructName(fn, node)
 				return false
 			}
 
 			// Check for json.NewDecoder(r.Body).Decode
 			if checkJSONDecoder(node) {
 				result.HasBody = true
-				result.BodyExample = generateSmartBodyExample(node, structInfo)
+				result.BodyExample = generateSmartBodyExample(node, structInfo, ex)
+				result.StructName = bodyStructName(fn, node)
 				return false
 			}
 
 			// Check for json.Unmarshal
 			if checkJSONUnmarshal(node) {
 				result.HasBody = true
-				result.BodyExample = generateSmartBodyExample(node, structInfo)
+				result.BodyExample = generateSmartBodyExample(node, structInfo, ex)
+				result.StructName = bodyStructName(fn, node)
 				return false
 			}
 
 			// Check for io.ReadAll pattern (often followed by json.Unmarshal)
 			if checkIOReadAll(node) {
 				result.HasBody = true
-				result.BodyExample = generateSmartBodyExample(node, structInfo)
+				result.BodyExample = generateSmartBodyExample(node, structInfo, ex)
 				return false
 			}
 		}
@@ -80,6 +95,19 @@ func DetectJSONBody(fn *ast.FuncDecl, fset *token.FileSet) BodyDetectionResult {
 	return result
 }
 
+// bodyStructName returns the qualified name of the project struct a body
+// is decoded into, or "" when it is not a known named struct
+func bodyStructName(fn *ast.FuncDecl, call *ast.CallExpr) string {
+	if len(call.Args) == 0 {
+		return ""
+	}
+	target := call.Args[0]
+	if checkJSONUnmarshal(call) && len(call.Args) > 1 {
+		target = call.Args[1]
+	}
+	return resolveTargetStruct(fn, target).QualifiedName()
+}
+
 // checkGinJSONBinding detects Gin framework JSON binding calls
 func checkGinJSONBinding(call *ast.CallExpr) bool {
 	if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
@@ -190,32 +218,7 @@ func analyzeInlineStruct(structType *ast.StructType, name string) *StructInfo {
 	}
 
 	for _, field := range structType.Fields.List {
-		fieldInfo := StructFieldInfo{
-			Required: true, // Default to required
-		}
-
-		// Get field names
-		if len(field.Names) > 0 {
-			fieldInfo.Name = field.Names[0].Name
-		}
-
-		// Get field type
-		fieldInfo.Type = getTypeString(field.Type)
-
-		// Get JSON tag if present
-		if field.Tag != nil {
-			tag := strings.Trim(field.Tag.Value, "`")
-			if strings.Contains(tag, "json:") {
-				fieldInfo.JSONTag = extractJSONTag(tag)
-			}
-		}
-
-		// If no JSON tag, use field name in lowercase
-		if fieldInfo.JSONTag == "" {
-			fieldInfo.JSONTag = strings.ToLower(fieldInfo.Name)
-		}
-
-		info.Fields = append(info.Fields, fieldInfo)
+		info.Fields = append(info.Fields, analyzeStructField(field)...)
 	}
 
 	return info
@@ -241,36 +244,90 @@ func getTypeString(expr ast.Expr) string {
 
 // extractJSONTag extracts the JSON field name from a struct tag
 func extractJSONTag(tag string) string {
-	// Look for json:"fieldname"
-	parts := strings.Split(tag, " ")
-	for _, part := range parts {
-		if strings.HasPrefix(part, "json:") {
-			jsonPart := strings.TrimPrefix(part, "json:")
-			jsonPart = strings.Trim(jsonPart, "\"")
-			// Handle json:",omitempty" or json:"fieldname,omitempty"
-			if idx := strings.Index(jsonPart, ","); idx != -1 {
-				jsonPart = jsonPart[:idx]
-			}
-			if jsonPart != "-" && jsonPart != "" {
-				return jsonPart
-			}
+	return parseJSONTag(tag).Name
+}
+
+// jsonTag is a json struct tag as encoding/json reads it
+type jsonTag struct {
+	Name      string // empty when the field keeps its Go name
+	Skip      bool   // json:"-"
+	OmitEmpty bool
+	OmitZero  bool
+	String    bool // numbers, bools and strings are encoded inside a string
+}
+
+func parseJSONTag(tag string) jsonTag {
+	value, ok := reflect.StructTag(tag).Lookup("json")
+	if !ok {
+		return jsonTag{}
+	}
+	if value == "-" {
+		return jsonTag{Skip: true}
+	}
+	name, opts, _ := strings.Cut(value, ",")
+	t := jsonTag{}
+	if isValidJSONTagName(name) {
+		t.Name = name
+	}
+	for opts != "" {
+		var opt string
+		opt, opts, _ = strings.Cut(opts, ",")
+		switch opt {
+		case "omitempty":
+			t.OmitEmpty = true
+		case "omitzero":
+			t.OmitZero = true
+		case "string":
+			t.String = true
 		}
 	}
-	return ""
+	return t
+}
+
+// isValidJSONTagName mirrors encoding/json: names with other characters
+// are ignored and the Go field name is used
+func isValidJSONTagName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:;<=>?@[]^_{|}~ ", r):
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return false
+		}
+	}
+	return true
+}
+
+// jsonFieldName is the key encoding/json writes for a field: the tag name
+// or the Go name as is
+func jsonFieldName(goName, tag string) string {
+	if name := extractJSONTag(tag); name != "" {
+		return name
+	}
+	return goName
 }
 
 // generateSmartBodyExample creates JSON based on actual struct analysis
-func generateSmartBodyExample(call *ast.CallExpr, structInfo *StructInfo) string {
+func generateSmartBodyExample(call *ast.CallExpr, structInfo *StructInfo, ex *exampleOptions) string {
+	// Type information knows exactly what the body is decoded into
+	if globalTypesInfo != nil {
+		if body := generateBodyFromTypes(call, globalTypesInfo, ex); body != "" {
+			return body
+		}
+	}
+
 	// Try to use project-wide analysis first
 	if globalProjectAnalysis != nil {
-		if body := generateBodyFromProjectAnalysis(call, globalProjectAnalysis); body != "" {
+		if body := generateBodyFromProjectAnalysis(call, globalProjectAnalysis, ex); body != "" {
 			return body
 		}
 	}
 
 	// Fallback to local struct analysis
 	if structInfo != nil && len(structInfo.Fields) > 0 {
-		return generateJSONFromStruct(structInfo)
+		return generateJSONFromStruct(structInfo, ex)
 	}
 
 	// Fallback to variable name analysis
@@ -294,18 +351,8 @@ func generateSmartBodyExample(call *ast.CallExpr, structInfo *StructInfo) string
 }
 
 // generateJSONFromStruct creates a JSON example from struct field information
-func generateJSONFromStruct(structInfo *StructInfo) string {
-	if len(structInfo.Fields) == 0 {
-		return `{}`
-	}
-
-	var jsonPairs []string
-	for _, field := range structInfo.Fields {
-		value := generateValueForType(field.Type)
-		jsonPairs = append(jsonPairs, fmt.Sprintf(`"%s":%s`, field.JSONTag, value))
-	}
-
-	return "{" + strings.Join(jsonPairs, ",") + "}"
+func generateJSONFromStruct(structInfo *StructInfo, ex *exampleOptions) string {
+	return generateJSONFromProjectStruct(&StructDefinition{Name: structInfo.Name, Fields: structInfo.Fields}, ex)
 }
 
 // generateValueForType generates an appropriate JSON value based on Go type
@@ -335,7 +382,7 @@ func generateValueForType(goType string) string {
 }
 
 // generateBodyFromProjectAnalysis generates JSON body using project-wide analysis
-func generateBodyFromProjectAnalysis(call *ast.CallExpr, analysis *ProjectAnalysis) string {
+func generateBodyFromProjectAnalysis(call *ast.CallExpr, analysis *ProjectAnalysis, ex *exampleOptions) string {
 	// Try to extract the variable type being decoded to
 	var targetTypeName string
 
@@ -351,7 +398,7 @@ func generateBodyFromProjectAnalysis(call *ast.CallExpr, analysis *ProjectAnalys
 					if strings.Contains(lowerStructName, lowerVarName) ||
 						strings.Contains(lowerVarName, lowerStructName) ||
 						isStructNameMatch(lowerVarName, lowerStructName) {
-						return generateJSONFromProjectStruct(structDef)
+						return generateJSONFromProjectStruct(structDef, ex)
 					}
 				}
 				targetTypeName = ident.Name
@@ -369,7 +416,7 @@ func generateBodyFromProjectAnalysis(call *ast.CallExpr, analysis *ProjectAnalys
 						if strings.Contains(lowerStructName, lowerVarName) ||
 							strings.Contains(lowerVarName, lowerStructName) ||
 							isStructNameMatch(lowerVarName, lowerStructName) {
-							return generateJSONFromProjectStruct(structDef)
+							return generateJSONFromProjectStruct(structDef, ex)
 						}
 					}
 					targetTypeName = ident.Name
@@ -383,7 +430,7 @@ func generateBodyFromProjectAnalysis(call *ast.CallExpr, analysis *ProjectAnalys
 		for _, dtoPattern := range analysis.ArchPattern.DTOPatterns {
 			if strings.Contains(strings.ToLower(dtoPattern), strings.ToLower(targetTypeName)) {
 				if structDef, exists := analysis.Structs[dtoPattern]; exists {
-					return generateJSONFromProjectStruct(structDef)
+					return generateJSONFromProjectStruct(structDef, ex)
 				}
 			}
 		}
@@ -392,6 +439,55 @@ func generateBodyFromProjectAnalysis(call *ast.CallExpr, analysis *ProjectAnalys
 	return ""
 }
 
+// generateBodyFromTypes generates JSON from the real type of the decode target
+func generateBodyFromTypes(call *ast.CallExpr, info *types.Info, ex *exampleOptions) string {
+	if len(call.Args) == 0 {
+		return ""
+	}
+	target := call.Args[0]
+	// For json.Unmarshal, second argument is the target
+	if checkJSONUnmarshal(call) && len(call.Args) > 1 {
+		target = call.Args[1]
+	}
+
+	t := info.TypeOf(target)
+	if t == nil {
+		return ""
+	}
+	if structDef := structFromType(t); structDef != nil {
+		return generateJSONFromProjectStruct(structDef, ex)
+	}
+	return ""
+}
+
+// structDefinitionFromTypes converts a type-checked struct into the same
+// shape analyzeStructField produces from source
+func structDefinitionFromTypes(st *types.Struct) *StructDefinition {
+	qualifier := func(p *types.Package) string { return p.Name() }
+	def := &StructDefinition{Tags: make(map[string]string)}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		fieldType := types.TypeString(f.Type(), qualifier)
+		if f.Embedded() {
+			def.Fields = append(def.Fields, StructFieldInfo{
+				Name:     f.Name(),
+				Type:     fieldType,
+				JSONTag:  embeddedJSONTag(st.Tag(i)),
+				Tag:      st.Tag(i),
+				Embedded: true,
+				Required: true,
+			})
+			continue
+		}
+		jsonTag := jsonFieldName(f.Name(), st.Tag(i))
+		if parseJSONTag(st.Tag(i)).Skip {
+			jsonTag = "-"
+		}
+		def.Fields = append(def.Fields, StructFieldInfo{Name: f.Name(), Type: fieldType, JSONTag: jsonTag, Tag: st.Tag(i), Required: true})
+	}
+	return def
+}
+
 // isStructNameMatch checks if variable name matches struct name patterns
 func isStructNameMatch(varName, structName string) bool {
 	// Remove common suffixes from struct names for matching
@@ -408,29 +504,6 @@ func isStructNameMatch(varName, structName string) bool {
 	return strings.Contains(varName, cleanStructName) || strings.Contains(cleanStructName, varName)
 }
 
-// generateJSONFromProjectStruct generates JSON from project-analyzed struct
-func generateJSONFromProjectStruct(structDef *StructDefinition) string {
-	if len(structDef.Fields) == 0 {
-		return `{}`
-	}
-
-	var jsonPairs []string
-	for _, field := range structDef.Fields {
-		if field.JSONTag == "-" {
-			continue // Skip fields marked as ignored
-		}
-
-		value := generateValueForType(field.Type)
-		jsonTag := field.JSONTag
-		if jsonTag == "" {
-			jsonTag = strings.ToLower(field.Name)
-		}
-		jsonPairs = append(jsonPairs, fmt.Sprintf(`"%s":%s`, jsonTag, value))
-	}
-
-	return "{" + strings.Join(jsonPairs, ",") + "}"
-}
-
 // DetectBodyFromFunction analyzes a function declaration and detects JSON body patterns
 func DetectBodyFromFunction(fn *ast.FuncDecl, fset *token.FileSet) string {
 	result := DetectJSONBody(fn, fset)
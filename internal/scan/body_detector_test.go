@@ -510,7 +510,7 @@ func parseCallExpr(t *testing.T, code string) *ast.CallExpr {
 	return callExpr
 }
 
-func TestScanFunctionsForBodies(t *testing.T) {
+func TestHandlerIndexBodies(t *testing.T) {
 	code := `
 package main
 
@@ -547,7 +547,14 @@ func ProcessUser(data []byte) {
 		t.Fatalf("Failed to parse code: %v", err)
 	}
 
-	results := scanFunctionsForBodies(file, fset)
+	hi := newHandlerIndex(nil, fset, nil)
+	hi.collect(file)
+	results := make(map[string]string)
+	for name := range hi.byName {
+		if details, _ := hi.lookupName(ast.NewIdent(name)); details.body != "" {
+			results[name] = details.body
+		}
+	}
 
 	// Check that we found the right functions with bodies
 	expectedFunctions := []string{"CreatePayment", "HandleWebhook", "ProcessUser"}
@@ -0,0 +1,262 @@
+package scan
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+// ConstValue is a constant declared with a named type, one member of an
+// enum such as StatusPending OrderStatus = "pending"
+type ConstValue struct {
+	Name  string
+	Value string // JSON literal: "pending", 0
+	File  string
+}
+
+// EnumField is a field of a body type whose named type has constants
+type EnumField struct {
+	Field  string   // JSON path: status, items[].kind
+	Type   string   // named type, e.g. models.OrderStatus
+	Values []string // JSON literals of the members
+}
+
+// analyzeConstDecl records the constants of a const block under their
+// named type. Specs without a type or values repeat the previous ones
+// with the next iota.
+func analyzeConstDecl(decl *ast.GenDecl, packageName, filePath string, analysis *ProjectAnalysis) {
+	var typ ast.Expr
+	var values []ast.Expr
+	known := make(map[string]constant.Value)
+	knownTypes := make(map[string]string)
+	for iota, spec := range decl.Specs {
+		vs, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if vs.Type != nil || len(vs.Values) > 0 {
+			typ, values = vs.Type, vs.Values
+		}
+		for i, name := range vs.Names {
+			if i >= len(values) {
+				break
+			}
+			v := evalConst(values[i], int64(iota), known)
+			if v.Kind() == constant.Unknown {
+				continue
+			}
+			known[name.Name] = v
+			typeName := constTypeName(typ, values[i], knownTypes)
+			if typeName == "" || name.Name == "_" {
+				continue
+			}
+			knownTypes[name.Name] = typeName
+			key := typeName
+			if !strings.Contains(typeName, ".") {
+				key = packageName + "." + typeName
+			}
+			analysis.Constants[key] = append(analysis.Constants[key], ConstValue{
+				Name:  name.Name,
+				Value: constJSON(v),
+				File:  filePath,
+			})
+		}
+	}
+}
+
+// constTypeName is the declared type of a constant, the type of a
+// conversion such as OrderStatus("pending"), or the type of a typed
+// constant of the block the value is computed from (PriorityHigh * 10)
+func constTypeName(typ, value ast.Expr, knownTypes map[string]string) string {
+	if typ != nil {
+		return getTypeString(typ)
+	}
+	name := ""
+	ast.Inspect(value, func(n ast.Node) bool {
+		if name != "" {
+			return false
+		}
+		switch v := n.(type) {
+		case *ast.CallExpr:
+			if len(v.Args) == 1 {
+				switch fun := v.Fun.(type) {
+				case *ast.Ident, *ast.SelectorExpr:
+					name = getTypeString(fun)
+					return false
+				}
+			}
+		case *ast.Ident:
+			name = knownTypes[v.Name]
+		}
+		return true
+	})
+	return name
+}
+
+// evalConst evaluates a constant expression; the result is Unknown for
+// expressions it does not handle
+func evalConst(expr ast.Expr, iota int64, known map[string]constant.Value) constant.Value {
+	unknown := constant.MakeUnknown()
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return constant.MakeFromLiteral(e.Value, e.Kind, 0)
+	case *ast.Ident:
+		switch e.Name {
+		case "iota":
+			return constant.MakeInt64(iota)
+		case "true", "false":
+			return constant.MakeBool(e.Name == "true")
+		}
+		if v, ok := known[e.Name]; ok {
+			return v
+		}
+	case *ast.ParenExpr:
+		return evalConst(e.X, iota, known)
+	case *ast.CallExpr:
+		// a conversion, T(x)
+		if len(e.Args) == 1 {
+			return evalConst(e.Args[0], iota, known)
+		}
+	case *ast.UnaryExpr:
+		x := evalConst(e.X, iota, known)
+		if x.Kind() == constant.Unknown {
+			return unknown
+		}
+		return constant.UnaryOp(e.Op, x, 0)
+	case *ast.BinaryExpr:
+		x, y := evalConst(e.X, iota, known), evalConst(e.Y, iota, known)
+		if x.Kind() == constant.Unknown || y.Kind() == constant.Unknown {
+			return unknown
+		}
+		switch e.Op {
+		case token.SHL, token.SHR:
+			if s, ok := constant.Uint64Val(y); ok {
+				return constant.Shift(x, e.Op, uint(s))
+			}
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			return constant.MakeBool(constant.Compare(x, e.Op, y))
+		case token.QUO:
+			if x.Kind() == constant.Int && y.Kind() == constant.Int {
+				if constant.Sign(y) == 0 {
+					return unknown
+				}
+				return constant.BinaryOp(x, token.QUO_ASSIGN, y) // integer division
+			}
+			return constant.BinaryOp(x, e.Op, y)
+		case token.REM:
+			if constant.Sign(y) == 0 {
+				return unknown
+			}
+			return constant.BinaryOp(x, e.Op, y)
+		default:
+			return constant.BinaryOp(x, e.Op, y)
+		}
+	}
+	return unknown
+}
+
+// constJSON writes a constant as a JSON literal
+func constJSON(v constant.Value) string {
+	switch v.Kind() {
+	case constant.String:
+		return jsonString(constant.StringVal(v))
+	case constant.Bool:
+		return strconv.FormatBool(constant.BoolVal(v))
+	case constant.Int:
+		return v.ExactString()
+	case constant.Float:
+		f, _ := constant.Float64Val(v)
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return v.ExactString()
+}
+
+// enumValues returns the constants of a named type written in package
+// pkg
+func enumValues(goType, pkg string) []ConstValue {
+	if globalProjectAnalysis == nil || goType == "" {
+		return nil
+	}
+	key := strings.TrimLeft(goType, "*")
+	if !strings.Contains(key, ".") {
+		if pkg == "" {
+			return nil
+		}
+		key = pkg + "." + key
+	}
+	return globalProjectAnalysis.Constants[key]
+}
+
+// enumMembers are the values of constants as text: pending for "pending"
+func enumMembers(values []ConstValue) []string {
+	members := make([]string, len(values))
+	for i, v := range values {
+		members[i] = v.Value
+		if s, err := strconv.Unquote(v.Value); err == nil {
+			members[i] = s
+		}
+	}
+	return members
+}
+
+// bodyEnums lists the fields of a body struct, including nested ones,
+// whose type has constants
+func bodyEnums(typeName string) []EnumField {
+	def := lookupProjectStruct(typeName)
+	if def == nil {
+		return nil
+	}
+	var fields []EnumField
+	collectEnumFields(def, "", map[string]bool{def.QualifiedName(): true}, &fields)
+	return fields
+}
+
+func collectEnumFields(def *StructDefinition, prefix string, visiting map[string]bool, out *[]EnumField) {
+	for _, f := range def.Fields {
+		tag := parseJSONTag(f.Tag)
+		if tag.Skip || (f.JSONTag == "-" && tag.Name != "-") {
+			continue
+		}
+		path := prefix
+		if !f.Embedded || tag.Name != "" {
+			if !ast.IsExported(f.Name) {
+				continue
+			}
+			path += jsonFieldName(f.Name, f.Tag)
+		}
+
+		goType := strings.TrimLeft(f.Type, "*")
+		for strings.HasPrefix(goType, "[]") {
+			goType = strings.TrimLeft(goType[2:], "*")
+			path += "[]"
+		}
+		if values := enumValues(goType, def.Package); len(values) > 0 {
+			e := EnumField{Field: path, Type: qualifiedTypeName(goType, def.Package)}
+			for _, v := range values {
+				e.Values = append(e.Values, v.Value)
+			}
+			*out = append(*out, e)
+			continue
+		}
+		nested := lookupFieldStruct(goType, def.Package)
+		if nested == nil || visiting[nested.QualifiedName()] || len(visiting) > maxExampleDepth {
+			continue
+		}
+		visiting[nested.QualifiedName()] = true
+		if path != prefix {
+			path += "."
+		}
+		collectEnumFields(nested, path, visiting, out)
+		delete(visiting, nested.QualifiedName())
+	}
+}
+
+// qualifiedTypeName is "pkg.Type" for a type written in package pkg
+func qualifiedTypeName(goType, pkg string) string {
+	if strings.Contains(goType, ".") || pkg == "" {
+		return goType
+	}
+	return pkg + "." + goType
+}
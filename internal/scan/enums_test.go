@@ -0,0 +1,95 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+)
+
+const enumTestModels = `package models
+
+type OrderStatus string
+
+const (
+	StatusPending OrderStatus = "pending"
+	StatusPaid    OrderStatus = "paid"
+	StatusShipped             = OrderStatus("shipped")
+)
+
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	_
+	PriorityHigh
+	PriorityUrgent = PriorityHigh * 10
+)
+
+type Flag uint8
+
+const (
+	FlagA Flag = 1 << iota
+	FlagB
+)
+
+const Untyped = "ignored"
+
+func Convert(s string) string { return s }
+
+const NotAType = len("x")
+
+type Item struct {
+	Priority Priority ` + "`json:\"priority\"`" + `
+}
+
+type Order struct {
+	Status   OrderStatus  ` + "`json:\"status\"`" + `
+	Urgency  Priority     ` + "`json:\"urgency\" validate:\"required\"`" + `
+	Flags    []Flag       ` + "`json:\"flags\"`" + `
+	Items    []Item       ` + "`json:\"items\"`" + `
+	Previous *OrderStatus ` + "`json:\"previous,omitempty\"`" + `
+}
+`
+
+func TestAnalyzeProject_Constants(t *testing.T) {
+	analysis := analyzeTestProject(t, map[string]string{"models/models.go": enumTestModels})
+
+	got := make(map[string][]string)
+	for key, values := range analysis.Constants {
+		for _, v := range values {
+			got[key] = append(got[key], v.Name+"="+v.Value)
+		}
+	}
+	want := map[string][]string{
+		"models.OrderStatus": {`StatusPending="pending"`, `StatusPaid="paid"`, `StatusShipped="shipped"`},
+		"models.Priority":    {"PriorityNone=0", "PriorityLow=1", "PriorityHigh=3", "PriorityUrgent=30"},
+		"models.Flag":        {"FlagA=1", "FlagB=2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Constants = %v", got)
+	}
+}
+
+func TestGenerateJSONFromProjectStruct_Enums(t *testing.T) {
+	analysis := analyzeTestProject(t, map[string]string{"models/models.go": enumTestModels})
+
+	// required rules out PriorityNone (0)
+	assertJSONEqual(t, generateJSONFromProjectStruct(analysis.Structs["models.Order"], nil), `{
+		"status": "pending",
+		"urgency": 1,
+		"flags": [1],
+		"items": [{"priority": 0}],
+		"previous": "pending"
+	}`)
+
+	want := []EnumField{
+		{Field: "status", Type: "models.OrderStatus", Values: []string{`"pending"`, `"paid"`, `"shipped"`}},
+		{Field: "urgency", Type: "models.Priority", Values: []string{"0", "1", "3", "30"}},
+		{Field: "flags[]", Type: "models.Flag", Values: []string{"1", "2"}},
+		{Field: "items[].priority", Type: "models.Priority", Values: []string{"0", "1", "3", "30"}},
+		{Field: "previous", Type: "models.OrderStatus", Values: []string{`"pending"`, `"paid"`, `"shipped"`}},
+	}
+	if got := bodyEnums("models.Order"); !reflect.DeepEqual(got, want) {
+		t.Errorf("bodyEnums = %+v", got)
+	}
+}
@@ -0,0 +1,200 @@
+package scan
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// handlerDetails is everything detected inside a handler function
+type handlerDetails struct {
+	body      string            // JSON body example
+	bodyType  string            // qualified name of the body struct, if known
+	query     []QueryParam      // query parameters read by the handler
+	headers   []HeaderParam     // headers and cookies read by the handler
+	responses []ResponseExample // responses written by the handler
+	auth      *AuthInfo         // @auth in the handler's doc comment
+	doc       string            // doc comment without annotation lines
+
+	// authScheme is "basic" or "bearer" when the function parses the
+	// Authorization header itself (middleware)
+	authScheme string
+}
+
+// detectHandler runs every handler detector on a function
+func detectHandler(fn *ast.FuncDecl, fset *token.FileSet, ex *exampleOptions) handlerDetails {
+	body := detectJSONBody(fn, fset, ex)
+	return handlerDetails{
+		body:      body.BodyExample,
+		bodyType:  body.StructName,
+		query:     DetectQueryParams(fn),
+		headers:   DetectRequestHeaders(fn),
+		responses: detectResponses(fn, ex),
+		auth:      docAuth(fn.Doc),
+		doc:       handlerDoc(fn.Doc),
+
+		authScheme: detectAuthScheme(fn),
+	}
+}
+
+// empty reports whether nothing was detected in the function
+func (d handlerDetails) empty() bool {
+	return d.body == "" && len(d.query) == 0 && len(d.headers) == 0 && len(d.responses) == 0 &&
+		d.auth == nil && d.doc == "" && d.authScheme == ""
+}
+
+// handlerDoc returns the text of a handler's doc comment, leaving out
+// annotation lines (@route, @header, @auth...)
+func handlerDoc(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	var lines []string
+	for _, line := range strings.Split(doc.Text(), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "@") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// handlerIndex maps handler functions to what they read from the request.
+// Without type information handlers are matched by name.
+type handlerIndex struct {
+	info     *types.Info
+	fset     *token.FileSet
+	examples *exampleOptions
+	byName   map[string][]namedHandler
+	byFunc   map[types.Object]handlerDetails
+}
+
+// namedHandler is one of the functions sharing a name in AST mode
+type namedHandler struct {
+	pkg     string // package name
+	handler bool   // has the signature of an HTTP handler
+	details handlerDetails
+}
+
+// handlerParamTypes are the parameter types that make a function an HTTP
+// handler
+var handlerParamTypes = map[string]bool{
+	"*gin.Context": true, "echo.Context": true, "*fiber.Ctx": true,
+	"http.ResponseWriter": true, "*http.Request": true,
+}
+
+// takesRequest reports whether fn has a handler parameter
+func takesRequest(fn *ast.FuncDecl) bool {
+	if fn.Type == nil || fn.Type.Params == nil {
+		return false
+	}
+	for _, field := range fn.Type.Params.List {
+		if handlerParamTypes[getTypeString(field.Type)] {
+			return true
+		}
+	}
+	return false
+}
+
+func newHandlerIndex(info *types.Info, fset *token.FileSet, ex *exampleOptions) *handlerIndex {
+	return &handlerIndex{
+		info:     info,
+		fset:     fset,
+		examples: ex,
+		byName:   make(map[string][]namedHandler),
+		byFunc:   make(map[types.Object]handlerDetails),
+	}
+}
+
+// collect runs the detectors on every function declared in file
+func (hi *handlerIndex) collect(file *ast.File) {
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name == nil {
+			continue
+		}
+		details := detectHandler(fn, hi.fset, hi.examples)
+		if hi.info == nil {
+			name := fn.Name.Name
+			hi.byName[name] = append(hi.byName[name], namedHandler{pkg: file.Name.Name, handler: takesRequest(fn), details: details})
+		} else if obj := hi.info.Defs[fn.Name]; obj != nil {
+			hi.byFunc[obj] = details
+		}
+	}
+}
+
+// lookup returns the details of the handler passed to a route call
+func (hi *handlerIndex) lookup(call *ast.CallExpr, echo bool) handlerDetails {
+	handler := routeHandler(call, echo)
+	if handler == nil {
+		return handlerDetails{}
+	}
+	details, _ := hi.lookupFunc(handler)
+	return details
+}
+
+// lookupFunc returns the details of a function expression: a function
+// literal, a declared function or method, or a call to a constructor whose
+// body (including the closure it returns) is analyzed
+func (hi *handlerIndex) lookupFunc(expr ast.Expr) (handlerDetails, bool) {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return hi.lookupFunc(e.X)
+	case *ast.FuncLit:
+		// Inline handlers: r.GET("/x", func(c *gin.Context) {...})
+		return detectHandler(&ast.FuncDecl{Name: ast.NewIdent(""), Type: e.Type, Body: e.Body}, hi.fset, hi.examples), true
+	case *ast.CallExpr:
+		// AuthMiddleware(secret)
+		if details, ok := hi.lookupFunc(e.Fun); ok {
+			return details, true
+		}
+	}
+
+	if hi.info == nil {
+		return hi.lookupName(expr)
+	}
+	if fn := handlerFunc(hi.info, expr); fn != nil {
+		details, ok := hi.byFunc[fn]
+		return details, ok
+	}
+	return handlerDetails{}, false
+}
+
+// lookupName picks among the functions named like expr: the one in the
+// package a pkg.Func selector names, otherwise the first HTTP handler or
+// the first one anything was detected in, so (*UserService).Create does
+// not hide (*UserHandler).Create. Details of different functions are
+// never combined.
+func (hi *handlerIndex) lookupName(expr ast.Expr) (handlerDetails, bool) {
+	var candidates []namedHandler
+	qualifier := ""
+	switch e := expr.(type) {
+	case *ast.Ident:
+		candidates = hi.byName[e.Name]
+	case *ast.SelectorExpr:
+		candidates = hi.byName[e.Sel.Name]
+		if x, ok := e.X.(*ast.Ident); ok {
+			qualifier = x.Name
+		}
+	}
+	if len(candidates) == 0 {
+		return handlerDetails{}, false
+	}
+	for _, c := range candidates {
+		if qualifier != "" && c.pkg == qualifier {
+			return c.details, true
+		}
+	}
+	for _, c := range candidates {
+		if c.handler {
+			return c.details, true
+		}
+	}
+	for _, c := range candidates {
+		if !c.details.empty() {
+			return c.details, true
+		}
+	}
+	return candidates[0].details, true
+}
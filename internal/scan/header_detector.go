@@ -0,0 +1,140 @@
+package scan
+
+import (
+	"go/ast"
+	"reflect"
+	"strings"
+)
+
+// HeaderParam is a request header or cookie read by a handler
+type HeaderParam struct {
+	Name        string
+	Cookie      bool   // read with r.Cookie / c.Cookie rather than as a header
+	Description string // (from -spec)
+}
+
+// headerBinders are methods that bind request headers into a struct, mapped
+// to the struct tag they read header names from
+var headerBinders = map[string]string{
+	"ShouldBindHeader": "header",    // gin
+	"BindHeader":       "header",    // gin
+	"ReqHeaderParser":  "reqHeader", // fiber
+}
+
+// DetectRequestHeaders finds the headers and cookies a handler reads, in
+// order of first use
+func DetectRequestHeaders(fn *ast.FuncDecl) []HeaderParam {
+	if fn == nil || fn.Body == nil {
+		return nil
+	}
+
+	var params []HeaderParam
+	seen := make(map[string]bool)
+	addParam := func(name string, cookie bool) {
+		if !queryNamePattern.MatchString(name) {
+			return
+		}
+		// header names are case-insensitive, cookie names are not
+		key := "header:" + strings.ToLower(name)
+		if cookie {
+			key = "cookie:" + name
+		}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		params = append(params, HeaderParam{Name: name, Cookie: cookie})
+	}
+
+	fiberCtx := fiberContextParams(fn)
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IndexExpr:
+			// r.Header["X-Name"]
+			if isRequestHeaderField(node.X) {
+				if name, ok := stringArg(node.Index); ok {
+					addParam(name, false)
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			method := sel.Sel.Name
+
+			if tagKey, ok := headerBinders[method]; ok && len(node.Args) == 1 {
+				structDef := resolveTargetStruct(fn, node.Args[0])
+				if structDef == nil {
+					return true
+				}
+				for _, field := range structDef.Fields {
+					if field.Name == "" || field.Embedded {
+						continue
+					}
+					name := strings.Split(reflect.StructTag(field.Tag).Get(tagKey), ",")[0]
+					if name == "-" {
+						continue
+					}
+					if name == "" {
+						name = field.Name
+					}
+					addParam(name, false)
+				}
+				return true
+			}
+
+			if len(node.Args) == 0 {
+				return true
+			}
+			name, ok := stringArg(node.Args[0])
+			if !ok {
+				return true
+			}
+			switch {
+			// r.Header.Get, c.Request().Header.Get, c.Request.Header.Values
+			case (method == "Get" || method == "Values") && isRequestHeaderField(sel.X):
+				addParam(name, false)
+			// gin c.GetHeader, fiber ctx.Get
+			case method == "GetHeader", method == "Get" && isIdentIn(sel.X, fiberCtx):
+				addParam(name, false)
+			// net/http r.Cookie, gin/echo c.Cookie, fiber ctx.Cookies
+			case method == "Cookie", method == "Cookies" && isIdentIn(sel.X, fiberCtx):
+				addParam(name, true)
+			}
+		}
+		return true
+	})
+	return params
+}
+
+// isRequestHeaderField matches the X.Header field of a request. The
+// response writer's w.Header() is a method call and does not match.
+func isRequestHeaderField(expr ast.Expr) bool {
+	sel, ok := expr.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == "Header"
+}
+
+// fiberContextParams returns the names of *fiber.Ctx parameters, whose Get
+// method reads a request header
+func fiberContextParams(fn *ast.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	if fn.Type == nil || fn.Type.Params == nil {
+		return names
+	}
+	for _, field := range fn.Type.Params.List {
+		if getTypeString(field.Type) != "*fiber.Ctx" {
+			continue
+		}
+		for _, name := range field.Names {
+			names[name.Name] = true
+		}
+	}
+	return names
+}
+
+func isIdentIn(expr ast.Expr, names map[string]bool) bool {
+	id, ok := expr.(*ast.Ident)
+	return ok && names[id.Name]
+}
@@ -0,0 +1,91 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanDir_DetectsRequestHeaders(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+type TraceHeaders struct {
+	RequestID string ` + "`header:\"X-Request-ID\"`" + `
+	Internal  string ` + "`header:\"-\"`" + `
+}
+
+func createOrder(c *gin.Context) {
+	var h TraceHeaders
+	_ = c.ShouldBindHeader(&h)
+	_ = c.GetHeader("Idempotency-Key")
+	_, _ = c.Cookie("session")
+}
+
+func getProfile(w http.ResponseWriter, r *http.Request) {
+	_ = r.Header.Get("X-Tenant-ID")
+	_ = r.Header.Get("x-tenant-id")
+	_, _ = r.Cookie("session")
+	w.Header().Set("X-Response", "1")
+}
+
+func listOrders(c echo.Context) error {
+	_ = c.Request().Header.Get("Accept-Language")
+	return nil
+}
+
+func listItems(ctx *fiber.Ctx) error {
+	_ = ctx.Get("X-Api-Key")
+	_ = ctx.Cookies("theme")
+	return nil
+}
+
+func main() {
+	r := gin.Default()
+	r.POST("/orders", createOrder)
+	http.HandleFunc("/profile", getProfile)
+	e := echo.New()
+	e.GET("/orders", listOrders)
+	app := fiber.New()
+	app.Get("/items", listItems)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string][]HeaderParam)
+	for _, e := range eps {
+		got[e.Method+" "+e.Path] = e.RequestHeaders
+	}
+
+	want := map[string][]HeaderParam{
+		"POST /orders": {{Name: "X-Request-ID"}, {Name: "Idempotency-Key"}, {Name: "session", Cookie: true}},
+		"ANY /profile": {{Name: "X-Tenant-ID"}, {Name: "session", Cookie: true}},
+		"GET /orders":  {{Name: "Accept-Language"}},
+		"GET /items":   {{Name: "X-Api-Key"}, {Name: "theme", Cookie: true}},
+	}
+	for key, params := range want {
+		if len(got[key]) != len(params) {
+			t.Errorf("%s: expected %v, got %v", key, params, got[key])
+			continue
+		}
+		for i := range params {
+			if got[key][i] != params[i] {
+				t.Errorf("%s: header %d expected %v, got %v", key, i, params[i], got[key][i])
+			}
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package scan
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// middlewareInfo is what the middleware attached to a route contributes
+type middlewareInfo struct {
+	names   []string
+	auth    *AuthInfo
+	headers []HeaderParam
+}
+
+// apply adds the middleware's auth and headers to an endpoint. Headers the
+// handler reads itself keep their position; auth set by @auth or another
+// source is left alone.
+func (mw middlewareInfo) apply(e Endpoint) Endpoint {
+	if len(mw.names) == 0 {
+		return e
+	}
+	e.Middleware = append(e.Middleware, mw.names...)
+	if e.Auth == nil {
+		e.Auth = mw.auth
+	}
+	for _, h := range mw.headers {
+		if !hasHeaderParam(e.RequestHeaders, h) {
+			e.RequestHeaders = append(e.RequestHeaders, h)
+		}
+	}
+	return e
+}
+
+func hasHeaderParam(params []HeaderParam, h HeaderParam) bool {
+	for _, p := range params {
+		if p.Cookie == h.Cookie && strings.EqualFold(p.Name, h.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// knownMiddleware maps well-known middleware constructors, by lower-case
+// "pkg.Func" name, to the auth they enforce
+var knownMiddleware = map[string]AuthInfo{
+	"jwtauth.verifier":               {Type: "bearer"}, // go-chi/jwtauth
+	"jwtauth.authenticator":          {Type: "bearer"},
+	"echojwt.jwt":                    {Type: "bearer"}, // labstack/echo-jwt
+	"echojwt.withconfig":             {Type: "bearer"},
+	"middleware.jwt":                 {Type: "bearer"}, // echo v4 middleware
+	"middleware.jwtwithconfig":       {Type: "bearer"},
+	"jwtware.new":                    {Type: "bearer"}, // gofiber/contrib/jwt
+	"gin.basicauth":                  {Type: "basic"},
+	"gin.basicauthforrealm":          {Type: "basic"},
+	"middleware.basicauth":           {Type: "basic"}, // chi and echo middleware
+	"middleware.basicauthwithconfig": {Type: "basic"},
+	"basicauth.new":                  {Type: "basic"},  // gofiber basicauth
+	"middleware.keyauth":             {Type: "bearer"}, // echo, Authorization: Bearer by default
+	"middleware.keyauthwithconfig":   {Type: "bearer"},
+	"keyauth.new":                    {Type: "bearer"}, // gofiber keyauth
+}
+
+// middleware returns the middleware expressions attached to the router
+// expression recv: arguments of Use on the router variable, With and extra
+// Group arguments, inherited from every parent group and mounting router.
+// Use calls after the route registered at route, in the same function, do
+// not apply to it.
+func (idx *routeIndex) middleware(recv ast.Expr, route token.Pos) []ast.Expr {
+	switch e := recv.(type) {
+	case *ast.ParenExpr:
+		return idx.middleware(e.X, route)
+	case *ast.StarExpr:
+		return idx.middleware(e.X, route)
+	case *ast.UnaryExpr:
+		return idx.middleware(e.X, route)
+	case *ast.Ident:
+		if obj := idx.info.Uses[e]; obj != nil {
+			return idx.objectMiddleware(obj, route)
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		name := sel.Sel.Name
+		if _, ok := groupMethods[name]; ok {
+			out := idx.middleware(sel.X, route)
+			if len(e.Args) > 1 {
+				out = append(out, middlewareArgs(e.Args[1:])...)
+			}
+			return out
+		}
+		if _, ok := passthroughMethods[name]; ok {
+			out := idx.middleware(sel.X, route)
+			if name == "With" {
+				out = append(out, middlewareArgs(e.Args)...)
+			}
+			return out
+		}
+	}
+	return nil
+}
+
+// middlewareArgs drops function literals, which are chi Route bodies or
+// inline handlers rather than middleware
+func middlewareArgs(args []ast.Expr) []ast.Expr {
+	var out []ast.Expr
+	for _, arg := range args {
+		if _, ok := arg.(*ast.FuncLit); ok {
+			continue
+		}
+		out = append(out, arg)
+	}
+	return out
+}
+
+func (idx *routeIndex) objectMiddleware(obj types.Object, route token.Pos) []ast.Expr {
+	if idx.visiting[obj] {
+		return nil
+	}
+	idx.visiting[obj] = true
+	defer delete(idx.visiting, obj)
+
+	var out []ast.Expr
+	for _, v := range idx.values[obj] {
+		out = append(out, idx.middleware(v, route)...)
+	}
+	if site, ok := idx.params[obj]; ok {
+		if site.lit != nil {
+			if call, ok := idx.litCalls[site.lit]; ok {
+				out = append(out, idx.middleware(call, route)...)
+			}
+		} else {
+			for _, call := range idx.calls[site.fn.Name.Name] {
+				if site.index < len(call.Args) {
+					out = append(out, idx.middleware(call.Args[site.index], route)...)
+				}
+			}
+		}
+	}
+	for _, call := range idx.mounts[obj] {
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
+			out = append(out, idx.middleware(sel.X, route)...)
+		}
+	}
+	for _, call := range idx.calls["Use"] {
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		if call.Pos() > route && idx.sameFunc(call.Pos(), route) {
+			continue // gin only applies Use to routes registered after it
+		}
+		if id, ok := sel.X.(*ast.Ident); ok && idx.info.Uses[id] == obj {
+			out = append(out, middlewareArgs(call.Args)...)
+		}
+	}
+	return out
+}
+
+// routeMiddleware resolves the middleware of a route registered by call on
+// recv, including the per-route middleware passed with the handler
+func (idx *routeIndex) routeMiddleware(recv ast.Expr, call *ast.CallExpr, echo bool, handlers *handlerIndex) middlewareInfo {
+	exprs := idx.middleware(recv, call.Pos())
+	exprs = append(exprs, middlewareArgs(routeMiddlewareArgs(call, echo))...)
+
+	var mw middlewareInfo
+	seen := make(map[string]bool)
+	for _, expr := range exprs {
+		name := middlewareName(expr)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		mw.names = append(mw.names, name)
+
+		auth, headers := classifyMiddleware(expr, name, handlers)
+		if mw.auth == nil {
+			mw.auth = auth
+		}
+		for _, h := range headers {
+			if !hasHeaderParam(mw.headers, h) {
+				mw.headers = append(mw.headers, h)
+			}
+		}
+	}
+	return mw
+}
+
+// middlewareName renders a middleware expression as pkg.Func or Func
+func middlewareName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			return x.Name + "." + e.Sel.Name
+		}
+		return e.Sel.Name
+	case *ast.CallExpr:
+		return middlewareName(e.Fun)
+	case *ast.ParenExpr:
+		return middlewareName(e.X)
+	}
+	return ""
+}
+
+// classifyMiddleware works out the auth a middleware enforces and the other
+// headers it reads. Well-known constructors are matched by name; project
+// middleware is analyzed like a handler, looking at the headers it reads.
+func classifyMiddleware(expr ast.Expr, name string, handlers *handlerIndex) (*AuthInfo, []HeaderParam) {
+	if auth, ok := knownMiddleware[strings.ToLower(name)]; ok {
+		return &auth, nil
+	}
+
+	details, found := handlers.lookupFunc(expr)
+	if !found {
+		// Unknown third-party middleware: fall back to its name
+		lower := strings.ToLower(name)
+		switch {
+		case strings.Contains(lower, "basicauth"):
+			return &AuthInfo{Type: "basic"}, nil
+		case strings.Contains(lower, "jwt") || strings.Contains(lower, "bearer"):
+			return &AuthInfo{Type: "bearer"}, nil
+		}
+		return nil, nil
+	}
+
+	var auth *AuthInfo
+	var headers []HeaderParam
+	for _, h := range details.headers {
+		lower := strings.ToLower(h.Name)
+		switch {
+		case h.Cookie:
+			headers = append(headers, h)
+		case lower == "authorization":
+			if auth == nil {
+				scheme := details.authScheme
+				if scheme == "" {
+					scheme = "bearer"
+				}
+				auth = &AuthInfo{Type: scheme}
+			}
+		case isAPIKeyHeader(lower):
+			if auth == nil {
+				auth = &AuthInfo{Type: "apikey", Key: h.Name}
+			}
+		default:
+			headers = append(headers, h)
+		}
+	}
+	if auth == nil && details.authScheme != "" {
+		auth = &AuthInfo{Type: details.authScheme}
+	}
+	return auth, headers
+}
+
+// isAPIKeyHeader matches X-API-Key, Api-Key, X-Auth-Token and similar
+func isAPIKeyHeader(lower string) bool {
+	compact := strings.NewReplacer("-", "", "_", "").Replace(lower)
+	return strings.Contains(compact, "apikey") || strings.HasSuffix(compact, "authtoken") || compact == "xtoken"
+}
+
+// detectAuthScheme notices r.BasicAuth() and "Bearer " prefixes, which tell
+// how a middleware reads the Authorization header
+func detectAuthScheme(fn *ast.FuncDecl) string {
+	if fn == nil || fn.Body == nil {
+		return ""
+	}
+	scheme := ""
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.CallExpr:
+			if isMethodCall(node, "BasicAuth") && len(node.Args) == 0 {
+				scheme = "basic"
+				return false
+			}
+		case *ast.BasicLit:
+			if s, ok := stringLit(node); ok && scheme == "" && strings.EqualFold(strings.TrimSpace(s), "bearer") {
+				scheme = "bearer"
+			}
+		}
+		return true
+	})
+	return scheme
+}
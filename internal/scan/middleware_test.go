@@ -0,0 +1,220 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScanDir_MiddlewareAuthAndHeaders(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func tenantMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = c.GetHeader("X-Tenant-ID")
+		_ = strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		c.Next()
+	}
+}
+
+func apiKeyAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.Header.Get("X-API-Key")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func main() {
+	r := gin.Default()
+	r.GET("/public", health)
+	api := r.Group("/api")
+	api.Use(tenantMiddleware())
+	api.GET("/orders", listOrders)
+
+	c := chi.NewRouter()
+	c.With(jwtauth.Verifier(tokenAuth)).Get("/me", me)
+	c.Route("/keys", func(r chi.Router) {
+		r.Use(apiKeyAuth)
+		r.Get("/list", listKeys)
+	})
+
+	e := echo.New()
+	admin := e.Group("/admin", middleware.BasicAuth(validate))
+	admin.GET("/stats", stats)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string]Endpoint)
+	for _, e := range eps {
+		got[e.Method+" "+e.Path] = e
+	}
+
+	tests := []struct {
+		route   string
+		auth    *AuthInfo
+		headers []string
+	}{
+		{route: "GET /public"},
+		{route: "GET /api/orders", auth: &AuthInfo{Type: "bearer"}, headers: []string{"X-Tenant-ID"}},
+		{route: "GET /me", auth: &AuthInfo{Type: "bearer"}},
+		{route: "GET /keys/list", auth: &AuthInfo{Type: "apikey", Key: "X-API-Key"}},
+		{route: "GET /admin/stats", auth: &AuthInfo{Type: "basic"}},
+	}
+	for _, tt := range tests {
+		e, ok := got[tt.route]
+		if !ok {
+			t.Errorf("missing %s", tt.route)
+			continue
+		}
+		switch {
+		case tt.auth == nil && e.Auth != nil:
+			t.Errorf("%s: unexpected auth %+v", tt.route, *e.Auth)
+		case tt.auth != nil && (e.Auth == nil || !reflect.DeepEqual(*e.Auth, *tt.auth)):
+			t.Errorf("%s: expected auth %+v, got %+v", tt.route, *tt.auth, e.Auth)
+		}
+		if len(e.RequestHeaders) != len(tt.headers) {
+			t.Errorf("%s: expected headers %v, got %v", tt.route, tt.headers, e.RequestHeaders)
+			continue
+		}
+		for i, h := range tt.headers {
+			if e.RequestHeaders[i].Name != h {
+				t.Errorf("%s: expected header %s, got %s", tt.route, h, e.RequestHeaders[i].Name)
+			}
+		}
+	}
+
+	if mw := got["GET /api/orders"].Middleware; len(mw) != 1 || mw[0] != "tenantMiddleware" {
+		t.Errorf("expected tenantMiddleware to be recorded, got %v", mw)
+	}
+}
+
+func TestScanDir_PerRouteMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func authRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = c.GetHeader("Authorization")
+		c.Next()
+	}
+}
+
+func create(c *gin.Context) {
+	var req struct {
+		Name string ` + "`json:\"name\"`" + `
+	}
+	c.ShouldBindJSON(&req)
+	_ = c.Query("dry_run")
+}
+
+func stats(c echo.Context) error {
+	_ = c.QueryParam("period")
+	return nil
+}
+
+func main() {
+	r := gin.Default()
+	r.POST("/items", authRequired(), create)
+
+	e := echo.New()
+	e.GET("/stats", stats, middleware.BasicAuth(validate))
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string]Endpoint)
+	for _, e := range eps {
+		got[e.Method+" "+e.Path] = e
+	}
+
+	items := got["POST /items"]
+	if items.Handler != "create" || items.BodyRaw != `{"name":"string"}` {
+		t.Errorf("expected the create handler and its body, got %q %q", items.Handler, items.BodyRaw)
+	}
+	if len(items.Query) != 1 || items.Query[0].Name != "dry_run" {
+		t.Errorf("expected the handler's query parameters, got %+v", items.Query)
+	}
+	if !reflect.DeepEqual(items.Middleware, []string{"authRequired"}) || items.Auth == nil || items.Auth.Type != "bearer" {
+		t.Errorf("expected authRequired middleware with bearer auth, got %v %+v", items.Middleware, items.Auth)
+	}
+
+	stats := got["GET /stats"]
+	if stats.Handler != "stats" || len(stats.Query) != 1 || stats.Query[0].Name != "period" {
+		t.Errorf("expected the stats handler, got %q %+v", stats.Handler, stats.Query)
+	}
+	if !reflect.DeepEqual(stats.Middleware, []string{"middleware.BasicAuth"}) || stats.Auth == nil || stats.Auth.Type != "basic" {
+		t.Errorf("expected echo per-route middleware, got %v %+v", stats.Middleware, stats.Auth)
+	}
+}
+
+func TestScanDir_UseAppliesToLaterRoutes(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import "github.com/gin-gonic/gin"
+
+func authRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		_ = c.GetHeader("Authorization")
+		c.Next()
+	}
+}
+
+func main() {
+	r := gin.Default()
+	r.GET("/healthz", health)
+	r.Use(authRequired())
+	r.GET("/orders", listOrders)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string]Endpoint)
+	for _, e := range eps {
+		got[e.Method+" "+e.Path] = e
+	}
+	if health := got["GET /healthz"]; health.Auth != nil || len(health.Middleware) != 0 {
+		t.Errorf("expected no middleware before Use, got %v %+v", health.Middleware, health.Auth)
+	}
+	if orders := got["GET /orders"]; orders.Auth == nil || orders.Auth.Type != "bearer" {
+		t.Errorf("expected bearer auth after Use, got %+v", orders.Auth)
+	}
+}
@@ -0,0 +1,161 @@
+package scan
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"path"
+	"strings"
+)
+
+// maxEvalDepth bounds how many declarations a path expression may follow
+const maxEvalDepth = 16
+
+// routePath folds a route path argument into a string: literals, constants,
+// string variables, "+" concatenation, fmt.Sprintf and path.Join. Parts that
+// cannot be resolved become {{placeholder}} segments named after the
+// expression. ok is false when nothing at all could be resolved.
+func (idx *routeIndex) routePath(expr ast.Expr) (string, bool) {
+	p, known := idx.evalString(expr, 0)
+	if !known {
+		return "", false
+	}
+	if strings.HasPrefix(p, "{{") {
+		p = "/" + p
+	}
+	return p, true
+}
+
+// evalString returns the string value of expr; known reports whether any
+// part of it was resolved rather than replaced by a placeholder
+func (idx *routeIndex) evalString(expr ast.Expr, depth int) (string, bool) {
+	if depth > maxEvalDepth {
+		return placeholder(expr), false
+	}
+
+	// Constant expressions are folded by the type checker, including
+	// constants declared in other files of the package
+	if tv, ok := idx.info.Types[expr]; ok && tv.Value != nil {
+		return constantString(tv.Value), true
+	}
+
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if s, ok := stringLit(e); ok {
+			return s, true
+		}
+		return e.Value, true
+	case *ast.ParenExpr:
+		return idx.evalString(e.X, depth+1)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			break
+		}
+		l, lk := idx.evalString(e.X, depth+1)
+		r, rk := idx.evalString(e.Y, depth+1)
+		return l + r, lk || rk
+	case *ast.Ident:
+		obj := idx.info.Uses[e]
+		if c, ok := obj.(*types.Const); ok {
+			return constantString(c.Val()), true
+		}
+		// Variables are followed only when they are assigned exactly once
+		if v, ok := obj.(*types.Var); ok && len(idx.values[v]) == 1 {
+			return idx.evalString(idx.values[v][0], depth+1)
+		}
+	case *ast.SelectorExpr:
+		if c, ok := idx.info.Uses[e.Sel].(*types.Const); ok {
+			return constantString(c.Val()), true
+		}
+	case *ast.CallExpr:
+		switch callName(e) {
+		case "fmt.Sprintf":
+			return idx.evalSprintf(e, depth)
+		case "path.Join":
+			var parts []string
+			known := false
+			for _, arg := range e.Args {
+				s, k := idx.evalString(arg, depth+1)
+				parts = append(parts, s)
+				known = known || k
+			}
+			return path.Join(parts...), known
+		}
+	}
+	return placeholder(expr), false
+}
+
+// evalSprintf formats simple fmt.Sprintf calls: every verb consumes the next
+// argument, which is folded like any other path expression
+func (idx *routeIndex) evalSprintf(call *ast.CallExpr, depth int) (string, bool) {
+	if len(call.Args) == 0 {
+		return placeholder(call), false
+	}
+	format, known := idx.evalString(call.Args[0], depth+1)
+	if !known {
+		return placeholder(call), false
+	}
+
+	args := call.Args[1:]
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			b.WriteByte(format[i])
+			continue
+		}
+		// skip flags, width and precision up to the verb
+		j := i + 1
+		for j < len(format) && strings.IndexByte("+-# 0123456789.", format[j]) >= 0 {
+			j++
+		}
+		if j >= len(format) {
+			break
+		}
+		if format[j] == '%' {
+			b.WriteByte('%')
+		} else if len(args) > 0 {
+			s, _ := idx.evalString(args[0], depth+1)
+			b.WriteString(s)
+			args = args[1:]
+		}
+		i = j
+	}
+	return b.String(), true
+}
+
+// constantString renders a constant the way %v would
+func constantString(v constant.Value) string {
+	if v.Kind() == constant.String {
+		return constant.StringVal(v)
+	}
+	return v.ExactString()
+}
+
+// callName returns "pkg.Func" for package-qualified calls
+func callName(call *ast.CallExpr) string {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return ""
+	}
+	if x, ok := sel.X.(*ast.Ident); ok {
+		return x.Name + "." + sel.Sel.Name
+	}
+	return ""
+}
+
+// placeholder names an unresolved path part as a Postman variable
+func placeholder(expr ast.Expr) string {
+	name := "param"
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e.Name
+	case *ast.SelectorExpr:
+		name = e.Sel.Name
+	case *ast.CallExpr:
+		if n := calleeName(e); n != "" {
+			name = n
+		}
+	}
+	return "{{" + name + "}}"
+}
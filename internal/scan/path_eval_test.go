@@ -0,0 +1,51 @@
+package scan
+
+import "testing"
+
+func TestScanDir_FoldsPathExpressions(t *testing.T) {
+	got := scanSource(t, map[string]string{
+		"paths.go": `package main
+
+const basePath = "/api"
+
+var usersPath = "/users"
+`,
+		"main.go": `package main
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/gin-gonic/gin"
+)
+
+const version = "v2"
+
+func main() {
+	r := gin.Default()
+	const prefix = "/internal"
+	r.GET(basePath+usersPath+"/:id", getUser)
+	r.GET(fmt.Sprintf("%s/health", prefix), health)
+	r.GET(fmt.Sprintf("/%s/items/%d", version, 7), item)
+	r.GET(path.Join(basePath, "orders"), listOrders)
+	r.GET(cfg.Prefix+"/dynamic", dynamic)
+
+	v2 := r.Group(basePath + "/" + version)
+	v2.POST(usersPath, createUser)
+}
+`,
+	})
+
+	for _, k := range []string{
+		"GET /api/users/:id",
+		"GET /internal/health",
+		"GET /v2/items/7",
+		"GET /api/orders",
+		"GET /{{Prefix}}/dynamic",
+		"POST /api/v2/users",
+	} {
+		if !got[k] {
+			t.Errorf("missing %s (got %v)", k, got)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package scan
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PathParam is a path parameter normalized from any router dialect:
+// :id (gin/echo/fiber), {id} and {id:[0-9]+} (chi/gorilla/net/http),
+// *path and {rest...} wildcards
+type PathParam struct {
+	Name     string // parameter name as used in the Postman URL (:Name)
+	Original string // parameter as written in the route: {id:[0-9]+}
+	Pattern  string // regex or fiber constraint, if any
+	Wildcard bool   // matches the rest of the path
+}
+
+var (
+	paramNameRe  = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*`)
+	digitsOnlyRe = regexp.MustCompile(`^\^?(\[0-9\]|\\d)(\+|\*|\{[0-9,]+\})?\$?$`)
+	alphaOnlyRe  = regexp.MustCompile(`^\^?\[(a-z|A-Z|a-zA-Z|A-Za-z)\](\+|\*|\{[0-9,]+\})?\$?$`)
+	uuidHintRe   = regexp.MustCompile(`(?i)uuid|guid|\[0-9a-f\]\{8\}|\[a-f0-9\]\{8\}`)
+	quantifierRe = regexp.MustCompile(`\{([0-9]+)(,[0-9]*)?\}\$?$`)
+)
+
+// ParamDoc documents a path parameter beyond what the route says
+type ParamDoc struct {
+	Description string
+	Example     string
+}
+
+// NormalizePath rewrites every path parameter into Postman's :name syntax
+// and returns the parameters in order of appearance. Postman variables
+// ({{name}}) are left untouched.
+func NormalizePath(p string) (string, []PathParam) {
+	var b strings.Builder
+	var params []PathParam
+	seen := map[string]bool{}
+	addParam := func(pp PathParam) {
+		b.WriteString(":" + pp.Name)
+		if !seen[pp.Name] {
+			seen[pp.Name] = true
+			params = append(params, pp)
+		}
+	}
+
+	segStart := true
+	for i := 0; i < len(p); {
+		c := p[i]
+		switch {
+		case strings.HasPrefix(p[i:], "{{"):
+			end := strings.Index(p[i:], "}}")
+			if end < 0 {
+				b.WriteString(p[i:])
+				return b.String(), params
+			}
+			b.WriteString(p[i : i+end+2])
+			i += end + 2
+		case c == '{':
+			end := matchingBrace(p, i)
+			if end < 0 {
+				b.WriteString(p[i:])
+				return b.String(), params
+			}
+			inner := p[i+1 : end]
+			pp := PathParam{Name: inner, Original: p[i : end+1]}
+			if k := strings.Index(inner, ":"); k >= 0 {
+				pp.Name, pp.Pattern = inner[:k], inner[k+1:]
+			}
+			if strings.HasSuffix(pp.Name, "...") {
+				pp.Name = strings.TrimSuffix(pp.Name, "...")
+				pp.Wildcard = true
+			}
+			if pp.Name == "" {
+				pp.Name = "param"
+			}
+			addParam(pp)
+			i = end + 1
+		case c == ':' && segStart:
+			// gin and echo take the whole segment as the name: :item-id
+			name := p[i+1:]
+			if k := strings.IndexAny(name, "/<"); k >= 0 {
+				name = name[:k]
+			}
+			name = strings.TrimSuffix(name, "?")
+			if name == "" {
+				b.WriteByte(c)
+				i++
+				break
+			}
+			j := i + 1 + len(name)
+			pp := PathParam{Name: name}
+			// fiber constraints and optional marker: :id<int>?
+			if j < len(p) && p[j] == '<' {
+				if k := strings.IndexByte(p[j:], '>'); k >= 0 {
+					pp.Pattern = p[j+1 : j+k]
+					j += k + 1
+				}
+			}
+			if j < len(p) && p[j] == '?' {
+				j++
+			}
+			pp.Original = p[i:j]
+			addParam(pp)
+			i = j
+		case c == '*' && segStart:
+			name := paramNameRe.FindString(p[i+1:])
+			j := i + 1 + len(name)
+			if name == "" {
+				name = "path"
+			}
+			addParam(PathParam{Name: name, Original: p[i:j], Wildcard: true})
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+		segStart = i > 0 && p[i-1] == '/'
+	}
+	return b.String(), params
+}
+
+// matchingBrace returns the index of the brace closing the one at open,
+// allowing nested quantifiers such as {id:[0-9]{3}}
+func matchingBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// ExampleValue derives an example value for a path parameter from its
+// constraint, falling back to its name
+func (pp PathParam) ExampleValue() string {
+	pattern := strings.ToLower(pp.Pattern)
+	switch {
+	case pattern == "int" || pattern == "int32" || pattern == "int64":
+		return "1"
+	case digitsOnlyRe.MatchString(pp.Pattern):
+		// honor fixed lengths such as [0-9]{3}
+		return repeatQuantified(pp.Pattern, "1", "1")
+	case pattern == "bool":
+		return "true"
+	case pattern == "float":
+		return "1.5"
+	case pattern == "guid" || uuidHintRe.MatchString(pp.Pattern):
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case pattern == "alpha":
+		return "abc"
+	case alphaOnlyRe.MatchString(pp.Pattern):
+		return repeatQuantified(pp.Pattern, "a", "abc")
+	case pattern == "datetime":
+		return "2024-01-01"
+	}
+
+	name := strings.ToLower(pp.Name)
+	switch {
+	case pp.Wildcard:
+		return "path/to/resource"
+	case strings.Contains(name, "uuid") || strings.Contains(name, "guid"):
+		return "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case isIDName(pp.Name):
+		return "1"
+	case strings.Contains(name, "page") || strings.Contains(name, "num") || strings.Contains(name, "count"):
+		return "1"
+	case strings.Contains(name, "slug"):
+		return "example-slug"
+	case strings.Contains(name, "email"):
+		return "user@example.com"
+	case strings.Contains(name, "date"):
+		return "2024-01-01"
+	case strings.Contains(name, "year"):
+		return "2024"
+	}
+	return pp.Name
+}
+
+// isIDName matches id, user_id, user-id, userId and userID, but not
+// words that merely end in "id" such as paid or grid
+func isIDName(name string) bool {
+	lower := strings.ToLower(name)
+	if lower == "id" || strings.HasSuffix(lower, "_id") || strings.HasSuffix(lower, "-id") {
+		return true
+	}
+	if n := len(name); n > 2 && (strings.HasSuffix(name, "Id") || strings.HasSuffix(name, "ID")) {
+		prev := rune(name[n-3])
+		return unicode.IsLower(prev) || unicode.IsDigit(prev)
+	}
+	return false
+}
+
+// repeatQuantified repeats unit as often as a trailing {n} or {n,m}
+// quantifier requires, returning fallback when there is none
+func repeatQuantified(pattern, unit, fallback string) string {
+	m := quantifierRe.FindStringSubmatch(pattern)
+	if m == nil {
+		return fallback
+	}
+	n, err := strconv.Atoi(m[1])
+	if err != nil || n < 1 || n > 64 {
+		return fallback
+	}
+	return strings.Repeat(unit, n)
+}
+
+// Description notes the route syntax the parameter was converted from
+func (pp PathParam) Description() string {
+	desc := "Path parameter"
+	if pp.Wildcard {
+		desc = "Wildcard path parameter"
+	}
+	if pp.Original != "" && pp.Original != ":"+pp.Name {
+		desc += " (route pattern: " + pp.Original + ")"
+	}
+	return desc
+}
@@ -0,0 +1,42 @@
+package scan
+
+import "testing"
+
+func TestNormalizePath(t *testing.T) {
+	tests := []struct {
+		in       string
+		want     string
+		examples map[string]string
+	}{
+		{"/users/:id", "/users/:id", map[string]string{"id": "1"}},
+		{"/users/{id}", "/users/:id", map[string]string{"id": "1"}},
+		{"/articles/{slug:[a-z]+}", "/articles/:slug", map[string]string{"slug": "abc"}},
+		{"/orders/{code:[0-9]{3}}", "/orders/:code", map[string]string{"code": "111"}},
+		{"/items/:item<int>?", "/items/:item", map[string]string{"item": "1"}},
+		{"/static/*filepath", "/static/:filepath", map[string]string{"filepath": "path/to/resource"}},
+		{"/files/{rest...}", "/files/:rest", map[string]string{"rest": "path/to/resource"}},
+		{"/docs/*", "/docs/:path", map[string]string{"path": "path/to/resource"}},
+		{"/tenants/{tenant_uuid}/users", "/tenants/:tenant_uuid/users", map[string]string{"tenant_uuid": "3fa85f64-5717-4562-b3fc-2c963f66afa6"}},
+		{"/{{Prefix}}/health", "/{{Prefix}}/health", nil},
+		{"/time:now", "/time:now", nil},
+		{"/orders/:order_id/items/:item-id", "/orders/:order_id/items/:item-id", map[string]string{"order_id": "1", "item-id": "1"}},
+		{"/users/{userId}/posts/{postID}", "/users/:userId/posts/:postID", map[string]string{"userId": "1", "postID": "1"}},
+		{"/invoices/:paid/:valid/:grid", "/invoices/:paid/:valid/:grid", map[string]string{"paid": "paid", "valid": "valid", "grid": "grid"}},
+	}
+
+	for _, tt := range tests {
+		got, params := NormalizePath(tt.in)
+		if got != tt.want {
+			t.Errorf("NormalizePath(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if len(params) != len(tt.examples) {
+			t.Errorf("NormalizePath(%q) returned %d params, want %d", tt.in, len(params), len(tt.examples))
+			continue
+		}
+		for _, pp := range params {
+			if want, ok := tt.examples[pp.Name]; !ok || pp.ExampleValue() != want {
+				t.Errorf("NormalizePath(%q): param %s example %q, want %q", tt.in, pp.Name, pp.ExampleValue(), want)
+			}
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"go/token"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +17,7 @@ type ProjectAnalysis struct {
 	Interfaces  map[string]*InterfaceDefinition
 	Functions   map[string]*FunctionInfo
 	Types       map[string]*TypeDefinition
+	Constants   map[string][]ConstValue // constants by named type ("pkg.Type"), in declaration order
 	Packages    map[string]*PackageInfo
 	ModuleName  string
 	ArchPattern ArchitecturePattern
@@ -32,6 +34,15 @@ type StructDefinition struct {
 	Tags       map[string]string
 }
 
+// QualifiedName returns the "pkg.Name" key of the struct in
+// ProjectAnalysis.Structs, or "" for anonymous structs
+func (s *StructDefinition) QualifiedName() string {
+	if s == nil || s.Package == "" || s.Name == "" {
+		return ""
+	}
+	return s.Package + "." + s.Name
+}
+
 // InterfaceDefinition contains information about interfaces
 type InterfaceDefinition struct {
 	Name       string
@@ -101,6 +112,7 @@ func AnalyzeProject(rootDir string) (*ProjectAnalysis, error) {
 		Interfaces: make(map[string]*InterfaceDefinition),
 		Functions:  make(map[string]*FunctionInfo),
 		Types:      make(map[string]*TypeDefinition),
+		Constants:  make(map[string][]ConstValue),
 		Packages:   make(map[string]*PackageInfo),
 	}
 
@@ -140,6 +152,14 @@ func AnalyzeProject(rootDir string) (*ProjectAnalysis, error) {
 	// Resolve type references across packages
 	resolveTypeReferences(analysis)
 
+	// Keep constants of the project's own named types; T(x) may also be a
+	// function call or a type from another module
+	for key := range analysis.Constants {
+		if _, ok := analysis.Types[key]; !ok {
+			delete(analysis.Constants, key)
+		}
+	}
+
 	return analysis, nil
 }
 
@@ -188,6 +208,10 @@ func analyzeFile(filePath string, fset *token.FileSet, analysis *ProjectAnalysis
 
 // analyzeGenDecl analyzes general declarations (types, vars, consts)
 func analyzeGenDecl(decl *ast.GenDecl, packageName, filePath string, analysis *ProjectAnalysis) {
+	if decl.Tok == token.CONST {
+		analyzeConstDecl(decl, packageName, filePath, analysis)
+		return
+	}
 	for _, spec := range decl.Specs {
 		switch s := spec.(type) {
 		case *ast.TypeSpec:
@@ -275,13 +299,19 @@ func analyzeStructField(field *ast.Field) []StructFieldInfo {
 
 	// Handle embedded fields or multiple fields with same type
 	if len(field.Names) == 0 {
-		// Embedded field
-		fields = append(fields, StructFieldInfo{
-			Name:     getTypeString(field.Type), // Use type as name for embedded
+		// Embedded field: named after its type, as encoding/json does for
+		// embedded types whose fields it does not promote
+		fieldInfo := StructFieldInfo{
+			Name:     embeddedName(fieldType),
 			Type:     fieldType,
-			JSONTag:  "",
+			Embedded: true,
 			Required: true,
-		})
+		}
+		if field.Tag != nil {
+			fieldInfo.Tag = structTag(field.Tag)
+			fieldInfo.JSONTag = embeddedJSONTag(fieldInfo.Tag)
+		}
+		fields = append(fields, fieldInfo)
 	} else {
 		// Named fields
 		for _, name := range field.Names {
@@ -291,15 +321,13 @@ func analyzeStructField(field *ast.Field) []StructFieldInfo {
 				Required: true,
 			}
 
-			// Extract JSON tag
+			// JSON key as encoding/json writes it
 			if field.Tag != nil {
-				tag := strings.Trim(field.Tag.Value, "`")
-				fieldInfo.JSONTag = extractJSONTag(tag)
-				if fieldInfo.JSONTag == "" {
-					fieldInfo.JSONTag = strings.ToLower(name.Name)
-				}
-			} else {
-				fieldInfo.JSONTag = strings.ToLower(name.Name)
+				fieldInfo.Tag = structTag(field.Tag)
+			}
+			fieldInfo.JSONTag = jsonFieldName(name.Name, fieldInfo.Tag)
+			if parseJSONTag(fieldInfo.Tag).Skip {
+				fieldInfo.JSONTag = "-"
 			}
 
 			fields = append(fields, fieldInfo)
@@ -309,6 +337,23 @@ func analyzeStructField(field *ast.Field) []StructFieldInfo {
 	return fields
 }
 
+// structTag returns the value of a struct tag literal, raw or quoted
+func structTag(lit *ast.BasicLit) string {
+	if tag, err := strconv.Unquote(lit.Value); err == nil {
+		return tag
+	}
+	return strings.Trim(lit.Value, "`")
+}
+
+// embeddedJSONTag is the JSONTag of an embedded field: "-" when skipped,
+// the tag name, or "" when its fields are promoted
+func embeddedJSONTag(tag string) string {
+	if parseJSONTag(tag).Skip {
+		return "-"
+	}
+	return extractJSONTag(tag)
+}
+
 // analyzeInterfaceMethod analyzes interface methods
 func analyzeInterfaceMethod(method *ast.Field) *MethodInfo {
 	if len(method.Names) == 0 {
@@ -371,6 +416,11 @@ func analyzeInterfaceMethod(method *ast.Field) *MethodInfo {
 func analyzeFuncDecl(decl *ast.FuncDecl, packageName, filePath string, analysis *ProjectAnalysis) {
 	funcName := decl.Name.Name
 	qualifiedName := packageName + "." + funcName
+	if decl.Recv != nil && len(decl.Recv.List) > 0 {
+		// methods are keyed "pkg.Type.Method" so same-named methods of
+		// different types don't collide
+		qualifiedName = packageName + "." + receiverTypeName(decl.Recv.List[0].Type) + "." + funcName
+	}
 
 	funcInfo := &FunctionInfo{
 		Name:       funcName,
@@ -447,6 +497,24 @@ func analyzeFuncDecl(decl *ast.FuncDecl, packageName, filePath string, analysis
 	analysis.Functions[qualifiedName] = funcInfo
 }
 
+// receiverTypeName returns T for a receiver of type T, *T or *T[K]
+func receiverTypeName(expr ast.Expr) string {
+	for {
+		switch t := expr.(type) {
+		case *ast.StarExpr:
+			expr = t.X
+		case *ast.ParenExpr:
+			expr = t.X
+		case *ast.IndexExpr:
+			expr = t.X
+		case *ast.IndexListExpr:
+			expr = t.X
+		default:
+			return getTypeString(expr)
+		}
+	}
+}
+
 // shouldSkipDir determines if a directory should be skipped
 func shouldSkipDir(dirName string) bool {
 	skipDirs := []string{
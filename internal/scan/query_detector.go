@@ -0,0 +1,196 @@
+package scan
+
+import (
+	"go/ast"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// QueryParam is a query string parameter read by a handler
+type QueryParam struct {
+	Name        string
+	Default     string // default value when the handler declares one
+	Example     string // example value (from -spec or validator rules)
+	Description string // (from -spec or validator rules)
+	Required    bool   // binding:"required" or validate:"required"
+}
+
+// queryGetters are context methods that read a single query parameter by
+// name (gin, echo, fiber). The second argument, when present, is the default.
+var queryGetters = map[string]bool{
+	"Query":         true, // gin, fiber
+	"DefaultQuery":  true, // gin
+	"GetQuery":      true, // gin
+	"QueryArray":    true, // gin
+	"GetQueryArray": true, // gin
+	"QueryParam":    true, // echo
+	"QueryInt":      true, // fiber
+	"QueryBool":     true, // fiber
+	"QueryFloat":    true, // fiber
+}
+
+// queryBinders are methods that bind the query string into a struct, mapped
+// to the struct tag they read field names from
+var queryBinders = map[string]string{
+	"ShouldBindQuery": "form",  // gin
+	"BindQuery":       "form",  // gin
+	"QueryParser":     "query", // fiber
+}
+
+// queryNamePattern filters out calls like db.Query("SELECT ...") that share a
+// method name with query getters
+var queryNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-\[\]]*$`)
+
+// DetectQueryParams finds the query parameters a handler reads, in order of
+// first use
+func DetectQueryParams(fn *ast.FuncDecl) []QueryParam {
+	if fn == nil || fn.Body == nil {
+		return nil
+	}
+
+	var params []QueryParam
+	seen := make(map[string]bool)
+	add := func(p QueryParam) {
+		if !queryNamePattern.MatchString(p.Name) || seen[p.Name] {
+			return
+		}
+		seen[p.Name] = true
+		params = append(params, p)
+	}
+	addParam := func(name, def string) {
+		add(QueryParam{Name: name, Default: def})
+	}
+
+	// Variables holding r.URL.Query(), e.g. q := r.URL.Query()
+	queryVars := make(map[string]bool)
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		assign, ok := n.(*ast.AssignStmt)
+		if !ok || len(assign.Lhs) != len(assign.Rhs) {
+			return true
+		}
+		for i, rhs := range assign.Rhs {
+			if id, ok := assign.Lhs[i].(*ast.Ident); ok && isURLQueryCall(rhs) {
+				queryVars[id.Name] = true
+			}
+		}
+		return true
+	})
+	isValues := func(expr ast.Expr) bool {
+		if id, ok := expr.(*ast.Ident); ok {
+			return queryVars[id.Name]
+		}
+		return isURLQueryCall(expr)
+	}
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.IndexExpr:
+			// q["name"]
+			if isValues(node.X) {
+				if name, ok := stringArg(node.Index); ok {
+					addParam(name, "")
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := node.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			method := sel.Sel.Name
+
+			// r.URL.Query().Get("name") and q.Get("name")
+			if (method == "Get" || method == "Has") && isValues(sel.X) {
+				if len(node.Args) > 0 {
+					if name, ok := stringArg(node.Args[0]); ok {
+						addParam(name, "")
+					}
+				}
+				return true
+			}
+
+			if queryGetters[method] && len(node.Args) > 0 {
+				name, ok := stringArg(node.Args[0])
+				if !ok {
+					return true
+				}
+				def := ""
+				if len(node.Args) > 1 {
+					def, _ = stringArg(node.Args[1])
+				}
+				addParam(name, def)
+				return true
+			}
+
+			if tagKey, ok := queryBinders[method]; ok && len(node.Args) == 1 {
+				structDef := resolveTargetStruct(fn, node.Args[0])
+				if structDef == nil {
+					return true
+				}
+				for _, field := range structDef.Fields {
+					name, def, ok := queryFieldName(field, tagKey)
+					if !ok {
+						continue
+					}
+					p := QueryParam{Name: name, Default: def}
+					kind := basicKind(field.Type, structDef.Package)
+					rules := ParseFieldRules(field.Tag).withEnum(enumValues(field.Type, structDef.Package), kind)
+					if !rules.IsZero() {
+						p.Required = rules.Required
+						p.Description = rules.Describe(schemaType(kind))
+						p.Example = rules.textExample(kind)
+					}
+					add(p)
+				}
+			}
+		}
+		return true
+	})
+	return params
+}
+
+// isURLQueryCall matches X.URL.Query()
+func isURLQueryCall(expr ast.Expr) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 0 {
+		return false
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Query" {
+		return false
+	}
+	inner, ok := sel.X.(*ast.SelectorExpr)
+	return ok && inner.Sel.Name == "URL"
+}
+
+// stringArg returns the value of a string literal argument
+func stringArg(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok {
+		return "", false
+	}
+	return stringLit(lit)
+}
+
+// queryFieldName returns the query parameter name bound to a struct field
+// and gin's default= option, if any
+func queryFieldName(field StructFieldInfo, tagKey string) (name, def string, ok bool) {
+	if field.Name == "" || field.Embedded { // embedded struct
+		return "", "", false
+	}
+	tag := reflect.StructTag(field.Tag).Get(tagKey)
+	if tag == "-" {
+		return "", "", false
+	}
+	parts := strings.Split(tag, ",")
+	name = parts[0]
+	if name == "" {
+		name = field.Name
+	}
+	for _, opt := range parts[1:] {
+		if strings.HasPrefix(opt, "default=") {
+			def = strings.TrimPrefix(opt, "default=")
+		}
+	}
+	return name, def, true
+}
@@ -0,0 +1,98 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanDir_DetectsQueryParams(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+	"github.com/labstack/echo/v4"
+)
+
+type UserFilter struct {
+	Page    int    ` + "`form:\"page,default=1\"`" + `
+	Role    string ` + "`form:\"role\"`" + `
+	Status  string ` + "`form:\"status\" binding:\"required,oneof=active banned\"`" + `
+	PerPage int    ` + "`form:\"per_page\" binding:\"gte=1,lte=100\"`" + `
+	Secret  string ` + "`form:\"-\"`" + `
+}
+
+func listUsers(c *gin.Context) {
+	var filter UserFilter
+	_ = c.ShouldBindQuery(&filter)
+	sort := c.DefaultQuery("sort", "name")
+	_ = c.Query("q")
+	_, _ = sort, db.Query("SELECT * FROM users")
+}
+
+func search(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	_ = q.Get("term")
+	_ = r.URL.Query().Get("limit")
+}
+
+func listOrders(c echo.Context) error {
+	_ = c.QueryParam("status")
+	return nil
+}
+
+func listItems(ctx *fiber.Ctx) error {
+	_ = ctx.Query("cursor", "0")
+	return nil
+}
+
+func main() {
+	r := gin.Default()
+	r.GET("/users", listUsers)
+	http.HandleFunc("/search", search)
+	e := echo.New()
+	e.GET("/orders", listOrders)
+	app := fiber.New()
+	app.Get("/items", listItems)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string][]QueryParam)
+	for _, e := range eps {
+		got[e.Path] = e.Query
+	}
+
+	want := map[string][]QueryParam{
+		"/users": {
+			{Name: "page", Default: "1"}, {Name: "role"},
+			{Name: "status", Example: "active", Description: "Required. One of: active, banned.", Required: true},
+			{Name: "per_page", Example: "1", Description: "Minimum 1. Maximum 100."},
+			{Name: "sort", Default: "name"}, {Name: "q"},
+		},
+		"/search": {{Name: "term"}, {Name: "limit"}},
+		"/orders": {{Name: "status"}},
+		"/items":  {{Name: "cursor", Default: "0"}},
+	}
+	for path, params := range want {
+		if len(got[path]) != len(params) {
+			t.Errorf("%s: expected %v, got %v", path, params, got[path])
+			continue
+		}
+		for i := range params {
+			if got[path][i] != params[i] {
+				t.Errorf("%s: param %d expected %v, got %v", path, i, params[i], got[path][i])
+			}
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ResponseExample is a response written by a handler
+type ResponseExample struct {
+	Status      int    // HTTP status code, 0 when it cannot be resolved
+	ContentType string // empty when there is no body
+	Body        string // example body
+	Type        string // qualified struct name ("models.User", "[]models.User"), if known
+}
+
+// Name describes the response the way Postman lists saved examples
+func (r ResponseExample) Name() string {
+	if r.Status == 0 {
+		return "Response"
+	}
+	if text := http.StatusText(r.Status); text != "" {
+		return fmt.Sprintf("%d %s", r.Status, text)
+	}
+	return strconv.Itoa(r.Status)
+}
+
+// statusCodes maps http.StatusXxx constant names (shared by fiber) to codes
+var statusCodes = func() map[string]int {
+	codes := map[string]int{
+		"StatusNonAuthoritativeInfo": http.StatusNonAuthoritativeInfo,
+		"StatusTeapot":               http.StatusTeapot,
+	}
+	for code := 100; code < 600; code++ {
+		text := http.StatusText(code)
+		if text == "" {
+			continue
+		}
+		name := strings.NewReplacer(" ", "", "-", "", "'", "").Replace(text)
+		codes["Status"+name] = code
+	}
+	return codes
+}()
+
+// jsonResponders are context methods writing a JSON body with the status as
+// first argument (gin, echo); fiber's single argument JSON is handled apart
+var jsonResponders = map[string]bool{
+	"JSON":                true,
+	"IndentedJSON":        true,
+	"PureJSON":            true,
+	"SecureJSON":          true,
+	"JSONPretty":          true,
+	"AbortWithStatusJSON": true,
+}
+
+// DetectResponses finds the responses a handler writes, in source order
+func DetectResponses(fn *ast.FuncDecl) []ResponseExample {
+	return detectResponses(fn, nil)
+}
+
+func detectResponses(fn *ast.FuncDecl, ex *exampleOptions) []ResponseExample {
+	if fn == nil || fn.Body == nil {
+		return nil
+	}
+
+	var responses []ResponseExample
+	seen := make(map[string]bool)
+	add := func(r ResponseExample) {
+		key := strconv.Itoa(r.Status) + " " + r.Body
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		responses = append(responses, r)
+	}
+	addJSON := func(status int, obj ast.Expr) {
+		add(ResponseExample{Status: status, ContentType: "application/json", Body: responseJSON(fn, obj, ex), Type: responseType(fn, obj)})
+	}
+	addText := func(status int, msg ast.Expr) {
+		body := "string"
+		if s, ok := stringArg(msg); ok {
+			body = s
+		}
+		add(ResponseExample{Status: status, ContentType: "text/plain", Body: body})
+	}
+
+	writers := responseWriterParams(fn)
+	// status set by w.WriteHeader or render.Status for the next body
+	// written; it applies to that body only
+	pending := http.StatusOK
+
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		// c.Status(204) on its own, not chained into fiber's Status(...).JSON
+		if stmt, ok := n.(*ast.ExprStmt); ok {
+			if call, ok := stmt.X.(*ast.CallExpr); ok && isMethodCall(call, "Status") && len(call.Args) == 1 {
+				add(ResponseExample{Status: statusCode(call.Args[0])})
+			}
+			return true
+		}
+
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+
+		switch callName(call) {
+		case "http.Error":
+			if len(call.Args) == 3 {
+				addText(statusCode(call.Args[2]), call.Args[1])
+			}
+			return true
+		case "render.Status":
+			if len(call.Args) == 2 {
+				pending = statusCode(call.Args[1])
+			}
+			return true
+		case "render.JSON":
+			if len(call.Args) == 3 {
+				addJSON(pending, call.Args[2])
+				pending = http.StatusOK
+			}
+			return true
+		}
+
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		switch method := sel.Sel.Name; {
+		case method == "WriteHeader" && len(call.Args) == 1:
+			pending = statusCode(call.Args[0])
+		case method == "Encode" && len(call.Args) == 1 && isResponseEncoder(sel.X, writers):
+			addJSON(pending, call.Args[0])
+			pending = http.StatusOK
+		case jsonResponders[method] && len(call.Args) >= 2:
+			addJSON(statusCode(call.Args[0]), call.Args[1])
+		case method == "JSON" && len(call.Args) == 1:
+			// fiber: ctx.JSON(v) or ctx.Status(400).JSON(v)
+			addJSON(chainedStatus(sel.X), call.Args[0])
+		case method == "String" && len(call.Args) >= 2:
+			addText(statusCode(call.Args[0]), call.Args[1])
+		case method == "SendString" && len(call.Args) == 1:
+			addText(chainedStatus(sel.X), call.Args[0])
+		case (method == "NoContent" || method == "SendStatus" || method == "AbortWithStatus") && len(call.Args) == 1:
+			add(ResponseExample{Status: statusCode(call.Args[0])})
+		}
+		return true
+	})
+	return responses
+}
+
+// isMethodCall reports whether call is x.name(...)
+func isMethodCall(call *ast.CallExpr, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	return ok && sel.Sel.Name == name
+}
+
+// chainedStatus returns the code of a fiber ctx.Status(code) receiver, and
+// 200 for a plain context
+func chainedStatus(recv ast.Expr) int {
+	if call, ok := recv.(*ast.CallExpr); ok && isMethodCall(call, "Status") && len(call.Args) == 1 {
+		return statusCode(call.Args[0])
+	}
+	return http.StatusOK
+}
+
+// statusCode resolves 201, http.StatusCreated or fiber.StatusCreated
+func statusCode(expr ast.Expr) int {
+	if globalTypesInfo != nil {
+		if tv, ok := globalTypesInfo.Types[expr]; ok && tv.Value != nil && tv.Value.Kind() == constant.Int {
+			if code, ok := constant.Int64Val(tv.Value); ok {
+				return int(code)
+			}
+		}
+	}
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind == token.INT {
+			code, _ := strconv.Atoi(e.Value)
+			return code
+		}
+	case *ast.SelectorExpr:
+		return statusCodes[e.Sel.Name]
+	case *ast.ParenExpr:
+		return statusCode(e.X)
+	}
+	return 0
+}
+
+// responseWriterParams returns the names of http.ResponseWriter parameters
+func responseWriterParams(fn *ast.FuncDecl) map[string]bool {
+	names := make(map[string]bool)
+	if fn.Type == nil || fn.Type.Params == nil {
+		return names
+	}
+	for _, field := range fn.Type.Params.List {
+		if getTypeString(field.Type) != "http.ResponseWriter" {
+			continue
+		}
+		for _, name := range field.Names {
+			names[name.Name] = true
+		}
+	}
+	return names
+}
+
+// isResponseEncoder matches json.NewEncoder(w) where w is the response
+// writer, so encoding into a buffer is not mistaken for a response
+func isResponseEncoder(expr ast.Expr, writers map[string]bool) bool {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || callName(call) != "json.NewEncoder" || len(call.Args) != 1 {
+		return false
+	}
+	if len(writers) == 0 {
+		return true
+	}
+	return isIdentIn(call.Args[0], writers)
+}
+
+// responseJSON builds an example JSON document for a value written as a
+// response: struct values use the project's struct definitions, map
+// literals such as gin.H{...} keep their keys
+func responseJSON(fn *ast.FuncDecl, expr ast.Expr, ex *exampleOptions) string {
+	if s, ok := exampleJSON(fn, expr, 0, ex); ok {
+		return s
+	}
+	return "{}"
+}
+
+func exampleJSON(fn *ast.FuncDecl, expr ast.Expr, depth int, ex *exampleOptions) (string, bool) {
+	if depth > maxEvalDepth {
+		return "", false
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return exampleJSON(fn, e.X, depth+1, ex)
+	case *ast.UnaryExpr:
+		return exampleJSON(fn, e.X, depth+1, ex)
+	case *ast.BasicLit:
+		if s, ok := stringLit(e); ok {
+			return jsonString(s), true
+		}
+		return e.Value, true
+	case *ast.CompositeLit:
+		if isMapLiteral(e) {
+			return mapLiteralJSON(fn, e, depth, ex), true
+		}
+		if e.Type != nil {
+			if s, ok := jsonForTypeExpr(e.Type, 0, ex); ok {
+				return s, true
+			}
+		}
+	case *ast.CallExpr:
+		// err.Error() and similar
+		if isMethodCall(e, "Error") && len(e.Args) == 0 {
+			return `"string"`, true
+		}
+	}
+
+	if globalTypesInfo != nil {
+		if t := globalTypesInfo.TypeOf(expr); t != nil {
+			return jsonForType(t, 0, ex), true
+		}
+	}
+	if id, ok := expr.(*ast.Ident); ok {
+		if typeExpr := localVarType(fn, id.Name); typeExpr != nil {
+			return jsonForTypeExpr(typeExpr, 0, ex)
+		}
+	}
+	return "", false
+}
+
+// responseType names the project struct a response value holds, prefixed
+// with [] for slices
+func responseType(fn *ast.FuncDecl, expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		case *ast.UnaryExpr:
+			expr = e.X
+			continue
+		}
+		break
+	}
+
+	if globalTypesInfo != nil {
+		if t := globalTypesInfo.TypeOf(expr); t != nil {
+			prefix := ""
+			if sl, ok := t.Underlying().(*types.Slice); ok {
+				prefix, t = "[]", sl.Elem()
+			}
+			if ptr, ok := t.(*types.Pointer); ok {
+				t = ptr.Elem()
+			}
+			if !isStructType(t) {
+				return ""
+			}
+			if name := structFromType(t).QualifiedName(); name != "" {
+				return prefix + name
+			}
+			return ""
+		}
+	}
+
+	var typeExpr ast.Expr
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		if isMapLiteral(e) {
+			return ""
+		}
+		typeExpr = e.Type
+	case *ast.Ident:
+		typeExpr = localVarType(fn, e.Name)
+	}
+	if typeExpr == nil {
+		return ""
+	}
+	if star, ok := typeExpr.(*ast.StarExpr); ok {
+		typeExpr = star.X
+	}
+	prefix := ""
+	if arr, ok := typeExpr.(*ast.ArrayType); ok {
+		prefix, typeExpr = "[]", arr.Elt
+		if star, ok := typeExpr.(*ast.StarExpr); ok {
+			typeExpr = star.X
+		}
+	}
+	if _, ok := typeExpr.(*ast.StructType); ok {
+		return ""
+	}
+	if name := lookupProjectStruct(getTypeString(typeExpr)).QualifiedName(); name != "" {
+		return prefix + name
+	}
+	return ""
+}
+
+func isStructType(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// isMapLiteral matches gin.H{}, fiber.Map{}, echo.Map{} and map[...]... literals
+func isMapLiteral(lit *ast.CompositeLit) bool {
+	switch t := lit.Type.(type) {
+	case *ast.MapType:
+		return true
+	case *ast.SelectorExpr:
+		return t.Sel.Name == "H" || t.Sel.Name == "Map"
+	}
+	if globalTypesInfo != nil {
+		if t := globalTypesInfo.TypeOf(lit); t != nil {
+			_, ok := t.Underlying().(*types.Map)
+			return ok
+		}
+	}
+	return false
+}
+
+func mapLiteralJSON(fn *ast.FuncDecl, lit *ast.CompositeLit, depth int, ex *exampleOptions) string {
+	var pairs []string
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := stringArg(kv.Key)
+		if !ok {
+			continue
+		}
+		value, ok := exampleJSON(fn, kv.Value, depth+1, ex)
+		if !ok {
+			value = `"string"`
+		}
+		pairs = append(pairs, jsonString(key)+":"+value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// jsonForTypeExpr builds example JSON from a type written in source
+func jsonForTypeExpr(typeExpr ast.Expr, depth int, ex *exampleOptions) (string, bool) {
+	if depth > maxEvalDepth {
+		return "", false
+	}
+	if wk, ok := LookupWellKnownType(getTypeString(typeExpr)); ok {
+		return wk.Example, true
+	}
+	switch t := typeExpr.(type) {
+	case *ast.StarExpr:
+		return jsonForTypeExpr(t.X, depth+1, ex)
+	case *ast.ArrayType:
+		elem, ok := jsonForTypeExpr(t.Elt, depth+1, ex)
+		if !ok {
+			return "[]", true
+		}
+		return "[" + elem + "]", true
+	case *ast.MapType:
+		return "{}", true
+	case *ast.StructType:
+		info := analyzeInlineStruct(t, "InlineStruct")
+		return generateJSONFromProjectStruct(&StructDefinition{Name: info.Name, Fields: info.Fields}, ex), true
+	}
+	if structDef := lookupProjectStruct(getTypeString(typeExpr)); structDef != nil {
+		return generateJSONFromProjectStruct(structDef, ex), true
+	}
+	if id, ok := typeExpr.(*ast.Ident); ok && types.Universe.Lookup(id.Name) != nil {
+		return generateValueForType(id.Name), true
+	}
+	return "", false
+}
+
+// jsonForType builds example JSON from a checked type
+func jsonForType(t types.Type, depth int, ex *exampleOptions) string {
+	if depth > maxEvalDepth {
+		return "{}"
+	}
+	if ptr, ok := t.(*types.Pointer); ok {
+		return jsonForType(ptr.Elem(), depth+1, ex)
+	}
+	typeName := types.TypeString(t, func(p *types.Package) string { return p.Name() })
+	if wk, ok := LookupWellKnownType(typeName); ok {
+		return wk.Example
+	}
+	if values := enumValues(typeName, ""); len(values) > 0 {
+		return values[0].Value
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		return generateJSONFromProjectStruct(structFromType(t), ex)
+	case *types.Slice:
+		return "[" + jsonForType(u.Elem(), depth+1, ex) + "]"
+	case *types.Array:
+		return "[" + jsonForType(u.Elem(), depth+1, ex) + "]"
+	case *types.Map:
+		return "{}"
+	case *types.Basic:
+		return generateValueForType(u.Name())
+	}
+	return `"string"`
+}
+
+func jsonString(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
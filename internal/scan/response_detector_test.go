@@ -0,0 +1,126 @@
+package scan
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScanDir_DetectsResponses(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OrderResponse struct {
+	ID    int    ` + "`json:\"id\"`" + `
+	State string ` + "`json:\"state\"`" + `
+}
+
+func createOrder(c *gin.Context) {
+	if c.Query("fail") != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order", "code": 42})
+		return
+	}
+	resp := OrderResponse{}
+	c.JSON(http.StatusCreated, resp)
+}
+
+func getOrder(c *gin.Context) {
+	c.String(404, "order not found")
+}
+
+func createUser(w http.ResponseWriter, r *http.Request) {
+	var out OrderResponse
+	w.WriteHeader(201)
+	json.NewEncoder(w).Encode(out)
+}
+
+func deleteItem(ctx *fiber.Ctx) error {
+	var errBody OrderResponse
+	if ctx.Params("id") == "" {
+		return ctx.Status(400).JSON(errBody)
+	}
+	return ctx.SendStatus(fiber.StatusNoContent)
+}
+
+func main() {
+	r := gin.Default()
+	r.POST("/orders", createOrder)
+	r.GET("/orders/:id", getOrder)
+	http.HandleFunc("/users", createUser)
+	app := fiber.New()
+	app.Delete("/items/:id", deleteItem)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string][]ResponseExample)
+	for _, e := range eps {
+		got[e.Method+" "+e.Path] = e.Responses
+	}
+
+	order := `{"id":0,"state":"string"}`
+	want := map[string][]ResponseExample{
+		"POST /orders": {
+			{Status: 400, ContentType: "application/json", Body: `{"error":"invalid order","code":42}`},
+			{Status: 201, ContentType: "application/json", Body: order, Type: "main.OrderResponse"},
+		},
+		"GET /orders/:id":   {{Status: 404, ContentType: "text/plain", Body: "order not found"}},
+		"ANY /users":        {{Status: 201, ContentType: "application/json", Body: order, Type: "main.OrderResponse"}},
+		"DELETE /items/:id": {{Status: 400, ContentType: "application/json", Body: order, Type: "main.OrderResponse"}, {Status: 204}},
+	}
+	for key, responses := range want {
+		if len(got[key]) != len(responses) {
+			t.Errorf("%s: expected %v, got %v", key, responses, got[key])
+			continue
+		}
+		for i := range responses {
+			if got[key][i] != responses[i] {
+				t.Errorf("%s: response %d expected %+v, got %+v", key, i, responses[i], got[key][i])
+			}
+		}
+	}
+
+	if name := (ResponseExample{Status: 201}).Name(); name != "201 Created" {
+		t.Errorf("expected name %q, got %q", "201 Created", name)
+	}
+}
+
+func TestDetectResponses_WriteHeaderAppliesToNextBody(t *testing.T) {
+	code := `package main
+
+func getOrder(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("id") == "" {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "missing id"})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]int{"id": 1})
+}
+`
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, 0)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	responses := DetectResponses(file.Decls[0].(*ast.FuncDecl))
+	if len(responses) != 2 || responses[0].Status != 400 || responses[1].Status != 200 {
+		t.Errorf("expected a 400 then a 200 response, got %+v", responses)
+	}
+}
@@ -0,0 +1,460 @@
+package scan
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// sourceFile is a parsed Go file together with the path it was read from
+type sourceFile struct {
+	path string
+	file *ast.File
+}
+
+// groupMethods create a sub-router whose routes live under the string argument
+// (gin/echo/fiber Group, chi Route, gorilla PathPrefix)
+var groupMethods = map[string]struct{}{
+	"Group": {}, "Route": {}, "PathPrefix": {},
+}
+
+// passthroughMethods return a router sharing the receiver's prefix
+var passthroughMethods = map[string]struct{}{
+	"Subrouter": {}, "With": {}, "Host": {}, "Schemes": {}, "Headers": {},
+}
+
+var rootPrefix = []string{""}
+
+// routeIndex links router variables back to the Group/Route/Mount/PathPrefix
+// calls that created them, so routes registered on nested groups or inside
+// helper functions resolve to their full path
+type routeIndex struct {
+	info     *types.Info
+	funcs    map[string][]*ast.FuncDecl     // function and method declarations by name
+	calls    map[string][]*ast.CallExpr     // call sites by callee name
+	litCalls map[*ast.FuncLit]*ast.CallExpr // calls receiving a function literal
+	values   map[types.Object][]ast.Expr    // values assigned to each variable
+	params   map[types.Object]paramSite     // function parameters
+	mounts   map[types.Object][]*ast.CallExpr
+	cache    map[types.Object][]string
+	visiting map[types.Object]bool
+}
+
+// paramSite locates a parameter within its function
+type paramSite struct {
+	fn    *ast.FuncDecl
+	lit   *ast.FuncLit
+	index int
+	typ   ast.Expr // declared type: *echo.Echo
+}
+
+// newRouteIndex indexes the given files. When info is nil the files are
+// type-checked without their imports, which is enough to link identifiers
+// to their declarations.
+func newRouteIndex(fset *token.FileSet, files []sourceFile, info *types.Info) *routeIndex {
+	if info == nil {
+		info = checkFilesLenient(fset, files)
+	}
+	idx := &routeIndex{
+		info:     info,
+		funcs:    make(map[string][]*ast.FuncDecl),
+		calls:    make(map[string][]*ast.CallExpr),
+		litCalls: make(map[*ast.FuncLit]*ast.CallExpr),
+		values:   make(map[types.Object][]ast.Expr),
+		params:   make(map[types.Object]paramSite),
+		mounts:   make(map[types.Object][]*ast.CallExpr),
+		cache:    make(map[types.Object][]string),
+		visiting: make(map[types.Object]bool),
+	}
+
+	var mountCalls []*ast.CallExpr
+	for _, sf := range files {
+		ast.Inspect(sf.file, func(n ast.Node) bool {
+			switch node := n.(type) {
+			case *ast.FuncDecl:
+				idx.funcs[node.Name.Name] = append(idx.funcs[node.Name.Name], node)
+				idx.addParams(node.Type, paramSite{fn: node})
+			case *ast.FuncLit:
+				idx.addParams(node.Type, paramSite{lit: node})
+			case *ast.AssignStmt:
+				if len(node.Lhs) == len(node.Rhs) {
+					for i, lhs := range node.Lhs {
+						if id, ok := lhs.(*ast.Ident); ok {
+							idx.addValue(id, node.Rhs[i])
+						}
+					}
+				}
+			case *ast.ValueSpec:
+				if len(node.Names) == len(node.Values) {
+					for i, name := range node.Names {
+						idx.addValue(name, node.Values[i])
+					}
+				}
+			case *ast.CallExpr:
+				if name := calleeName(node); name != "" {
+					idx.calls[name] = append(idx.calls[name], node)
+					if name == "Mount" && len(node.Args) >= 2 {
+						mountCalls = append(mountCalls, node)
+					}
+				}
+				for _, arg := range node.Args {
+					if lit, ok := arg.(*ast.FuncLit); ok {
+						idx.litCalls[lit] = node
+					}
+				}
+			}
+			return true
+		})
+	}
+
+	// Mount("/admin", sub) or Mount("/admin", adminRouter()): the mounted
+	// router is the variable itself or whatever the constructor returns
+	for _, call := range mountCalls {
+		for _, obj := range idx.mountTargets(call.Args[1]) {
+			idx.mounts[obj] = append(idx.mounts[obj], call)
+		}
+	}
+
+	return idx
+}
+
+func (idx *routeIndex) addParams(ft *ast.FuncType, site paramSite) {
+	if ft.Params == nil {
+		return
+	}
+	i := 0
+	for _, field := range ft.Params.List {
+		if len(field.Names) == 0 {
+			i++
+			continue
+		}
+		for _, name := range field.Names {
+			if obj := idx.info.Defs[name]; obj != nil {
+				s := site
+				s.index, s.typ = i, field.Type
+				idx.params[obj] = s
+			}
+			i++
+		}
+	}
+}
+
+func (idx *routeIndex) addValue(id *ast.Ident, value ast.Expr) {
+	obj := idx.info.Defs[id]
+	if obj == nil {
+		obj = idx.info.Uses[id]
+	}
+	if obj != nil {
+		idx.values[obj] = append(idx.values[obj], value)
+	}
+}
+
+// routerPackage returns the import path of the package a router
+// expression comes from: its type's package, or the package of the
+// constructor or parameter type it was obtained from
+func (idx *routeIndex) routerPackage(expr ast.Expr) string {
+	if tv, ok := idx.info.Types[expr]; ok && tv.Type != nil {
+		t := tv.Type
+		if ptr, ok := t.(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+		if named, ok := t.(*types.Named); ok && named.Obj().Pkg() != nil {
+			return named.Obj().Pkg().Path()
+		}
+	}
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return idx.routerPackage(e.X)
+	case *ast.StarExpr:
+		return idx.routerPackage(e.X)
+	case *ast.UnaryExpr:
+		return idx.routerPackage(e.X)
+	case *ast.CallExpr:
+		return idx.routerPackage(e.Fun)
+	case *ast.SelectorExpr:
+		if id, ok := e.X.(*ast.Ident); ok {
+			if pkg, ok := idx.info.Uses[id].(*types.PkgName); ok {
+				return pkg.Imported().Path()
+			}
+		}
+		return idx.routerPackage(e.X)
+	case *ast.Ident:
+		obj := idx.info.Uses[e]
+		if obj == nil || idx.visiting[obj] {
+			return ""
+		}
+		idx.visiting[obj] = true
+		defer delete(idx.visiting, obj)
+		for _, v := range idx.values[obj] {
+			if pkg := idx.routerPackage(v); pkg != "" {
+				return pkg
+			}
+		}
+		if site, ok := idx.params[obj]; ok && site.typ != nil {
+			return idx.routerPackage(site.typ)
+		}
+	}
+	return ""
+}
+
+// sameFunc reports whether two positions lie in the same function
+// declaration
+func (idx *routeIndex) sameFunc(a, b token.Pos) bool {
+	for _, fns := range idx.funcs {
+		for _, fn := range fns {
+			if fn.Pos() <= a && a < fn.End() {
+				return fn.Pos() <= b && b < fn.End()
+			}
+		}
+	}
+	return false
+}
+
+// isEcho reports whether a router is echo's, whose route methods take the
+// handler before the per-route middleware
+func (idx *routeIndex) isEcho(recv ast.Expr) bool {
+	return trimMajorVersion(idx.routerPackage(recv)) == "github.com/labstack/echo"
+}
+
+func (idx *routeIndex) mountTargets(arg ast.Expr) []types.Object {
+	switch a := arg.(type) {
+	case *ast.Ident:
+		if obj := idx.info.Uses[a]; obj != nil {
+			return []types.Object{obj}
+		}
+	case *ast.CallExpr:
+		var out []types.Object
+		for _, fn := range idx.funcs[calleeName(a)] {
+			if fn.Body == nil {
+				continue
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if _, ok := n.(*ast.FuncLit); ok {
+					return false
+				}
+				if ret, ok := n.(*ast.ReturnStmt); ok && len(ret.Results) > 0 {
+					if id, ok := ret.Results[0].(*ast.Ident); ok {
+						if obj := idx.info.Uses[id]; obj != nil {
+							out = append(out, obj)
+						}
+					}
+				}
+				return true
+			})
+		}
+		return out
+	}
+	return nil
+}
+
+// paths returns the full paths a route registered as p on recv resolves to
+func (idx *routeIndex) paths(recv ast.Expr, p string) []string {
+	var out []string
+	for _, prefix := range idx.prefixes(recv) {
+		relative := prefix != "" && (p == "" || strings.HasPrefix(p, "/"))
+		if !relative && !isValidEndpointPath(p) {
+			continue
+		}
+		full := joinRoutePath(prefix, p)
+		if isValidEndpointPath(full) {
+			out = append(out, full)
+		}
+	}
+	return out
+}
+
+// prefixes returns every path prefix the router expression can carry
+func (idx *routeIndex) prefixes(expr ast.Expr) []string {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return idx.prefixes(e.X)
+	case *ast.StarExpr:
+		return idx.prefixes(e.X)
+	case *ast.UnaryExpr:
+		return idx.prefixes(e.X)
+	case *ast.Ident:
+		if obj := idx.info.Uses[e]; obj != nil {
+			return idx.objectPrefixes(obj)
+		}
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		if _, ok := groupMethods[sel.Sel.Name]; ok {
+			base := idx.prefixes(sel.X)
+			if len(e.Args) > 0 {
+				if p, ok := idx.routePath(e.Args[0]); ok {
+					return joinPrefixes(base, []string{p})
+				}
+			}
+			return base
+		}
+		if _, ok := passthroughMethods[sel.Sel.Name]; ok {
+			return idx.prefixes(sel.X)
+		}
+	}
+	return rootPrefix
+}
+
+func (idx *routeIndex) objectPrefixes(obj types.Object) []string {
+	if cached, ok := idx.cache[obj]; ok {
+		return cached
+	}
+	if idx.visiting[obj] {
+		return rootPrefix
+	}
+	idx.visiting[obj] = true
+	defer delete(idx.visiting, obj)
+
+	var base []string
+	for _, v := range idx.values[obj] {
+		base = append(base, idx.prefixes(v)...)
+	}
+	if site, ok := idx.params[obj]; ok {
+		base = append(base, idx.paramPrefixes(site)...)
+	}
+	if len(base) == 0 {
+		base = rootPrefix
+	}
+
+	if mounts := idx.mounts[obj]; len(mounts) > 0 {
+		var mounted []string
+		for _, call := range mounts {
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				continue
+			}
+			p, ok := idx.routePath(call.Args[0])
+			if !ok {
+				continue
+			}
+			mounted = append(mounted, joinPrefixes(idx.prefixes(sel.X), []string{p})...)
+		}
+		if len(mounted) > 0 {
+			base = joinPrefixes(mounted, base)
+		}
+	}
+
+	result := uniqueStrings(base)
+	idx.cache[obj] = result
+	return result
+}
+
+// paramPrefixes resolves a router parameter from the calls that supply it
+func (idx *routeIndex) paramPrefixes(site paramSite) []string {
+	if site.lit != nil {
+		// chi Route("/v1", func(r chi.Router) {...}) and friends
+		if call, ok := idx.litCalls[site.lit]; ok {
+			return idx.prefixes(call)
+		}
+		return nil
+	}
+
+	var out []string
+	for _, call := range idx.calls[site.fn.Name.Name] {
+		if site.index < len(call.Args) {
+			out = append(out, idx.prefixes(call.Args[site.index])...)
+		}
+	}
+	return out
+}
+
+// calleeName returns the function or method name a call refers to
+func calleeName(call *ast.CallExpr) string {
+	switch fun := call.Fun.(type) {
+	case *ast.Ident:
+		return fun.Name
+	case *ast.SelectorExpr:
+		return fun.Sel.Name
+	}
+	return ""
+}
+
+func stringLit(expr ast.Expr) (string, bool) {
+	bl, ok := expr.(*ast.BasicLit)
+	if !ok || bl.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(bl.Value)
+	return s, err == nil
+}
+
+// joinRoutePath joins a group prefix and a relative route like gin's joinPaths
+func joinRoutePath(prefix, p string) string {
+	if prefix == "" {
+		return p
+	}
+	if p == "" {
+		return prefix
+	}
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(p, "/")
+}
+
+func joinPrefixes(bases, rels []string) []string {
+	var out []string
+	for _, b := range bases {
+		for _, r := range rels {
+			out = append(out, joinRoutePath(b, r))
+		}
+	}
+	return uniqueStrings(out)
+}
+
+func uniqueStrings(ss []string) []string {
+	seen := make(map[string]struct{}, len(ss))
+	out := make([]string, 0, len(ss))
+	for _, s := range ss {
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		out = append(out, s)
+	}
+	return out
+}
+
+// checkFilesLenient type-checks each package without loading its imports.
+// The result is full of errors, but identifier uses and definitions are
+// still linked and constants declared in the package are still folded,
+// which is all the route index needs.
+func checkFilesLenient(fset *token.FileSet, files []sourceFile) *types.Info {
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Defs:  make(map[*ast.Ident]types.Object),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+
+	type pkgKey struct{ dir, name string }
+	var order []pkgKey
+	groups := make(map[pkgKey][]*ast.File)
+	for _, sf := range files {
+		k := pkgKey{path.Dir(strings.ReplaceAll(sf.path, "\\", "/")), sf.file.Name.Name}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		groups[k] = append(groups[k], sf.file)
+	}
+
+	conf := types.Config{
+		Importer:    emptyImporter{},
+		Error:       func(error) {},
+		FakeImportC: true,
+	}
+	for _, k := range order {
+		_, _ = conf.Check(k.dir, fset, groups[k], info)
+	}
+	return info
+}
+
+// emptyImporter satisfies imports with empty packages
+type emptyImporter struct{}
+
+func (emptyImporter) Import(importPath string) (*types.Package, error) {
+	pkg := types.NewPackage(importPath, path.Base(trimMajorVersion(importPath)))
+	pkg.MarkComplete()
+	return pkg, nil
+}
@@ -0,0 +1,111 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func scanSource(t *testing.T, files map[string]string) map[string]bool {
+	t.Helper()
+	dir := t.TempDir()
+	for name, code := range files {
+		fp := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(fp, []byte(code), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	got := make(map[string]bool)
+	for _, e := range eps {
+		got[strings.ToUpper(e.Method)+" "+e.Path] = true
+	}
+	return got
+}
+
+func TestScanDir_RouterGroupPrefixes(t *testing.T) {
+	got := scanSource(t, map[string]string{
+		"main.go": `package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/mux"
+	"github.com/labstack/echo/v4"
+)
+
+func main() {
+	r := gin.Default()
+	api := r.Group("/api/v1")
+	api.GET("/health", health)
+	admin := api.Group("/admin")
+	admin.DELETE("/users/:id", deleteUser)
+	registerUserRoutes(api.Group("/users"))
+	r.Group("/legacy").POST("/import", importData)
+
+	c := chi.NewRouter()
+	c.Route("/v2", func(r chi.Router) {
+		r.Get("/books", listBooks)
+		r.Route("/authors", func(r chi.Router) {
+			r.Get("/{id}", getAuthor)
+		})
+	})
+	c.Mount("/internal", internalRouter())
+
+	e := echo.New()
+	g := e.Group("/v3", authMiddleware)
+	g.GET("/status", status)
+
+	m := mux.NewRouter()
+	sub := m.PathPrefix("/v4").Subrouter()
+	sub.HandleFunc("/orders", listOrders).Methods("GET")
+}
+
+func internalRouter() chi.Router {
+	r := chi.NewRouter()
+	r.Get("/metrics", metrics)
+	return r
+}
+`,
+		"routes/users.go": `package main
+
+import "github.com/gin-gonic/gin"
+
+func registerUserRoutes(rg *gin.RouterGroup) {
+	rg.GET("", listUsers)
+	rg.POST("/:id/avatar", uploadAvatar)
+}
+`,
+	})
+
+	want := []string{
+		"GET /api/v1/health",
+		"DELETE /api/v1/admin/users/:id",
+		"GET /api/v1/users",
+		"POST /api/v1/users/:id/avatar",
+		"POST /legacy/import",
+		"GET /v2/books",
+		"GET /v2/authors/{id}",
+		"GET /internal/metrics",
+		"GET /v3/status",
+		"GET /v4/orders",
+	}
+	for _, k := range want {
+		if !got[k] {
+			t.Errorf("missing %s (got %v)", k, got)
+		}
+	}
+	for _, k := range []string{"GET /health", "GET /books", "GET /metrics", "GET /status"} {
+		if got[k] {
+			t.Errorf("unexpected unprefixed route %s", k)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -13,16 +14,27 @@ import (
 )
 
 type Endpoint struct {
-	Method     string            // HTTP method: GET, POST, etc.
-	Path       string            // Path: /v1/users/{id}
-	SourceFile string            // Source file where it was detected
-	Handler    string            // Handler name when available
-	Desc       string            // Optional description (from @route)
-	Headers    map[string]string // @header Key: Value
-	BodyRaw    string            // @body {...} (raw JSON - single line)
-	Tags       []string          // @tag users
-	Type       string            // "REST", "GraphQL", "RPC"
-	GraphQL    *GraphQLInfo      // GraphQL specific information
+	Method         string              // HTTP method: GET, POST, etc.
+	Path           string              // Path: /v1/users/{id}
+	Host           string              // Host the route is restricted to (net/http "example.com/path" patterns)
+	SourceFile     string              // Source file where it was detected
+	Line           int                 // Line of the route registration or @route annotation
+	Handler        string              // Handler name when available
+	Desc           string              // Optional description (from @route)
+	Doc            string              // Doc comment of the handler, without annotations
+	Headers        map[string]string   // @header Key: Value
+	BodyRaw        string              // @body {...} (raw JSON - single line)
+	BodyType       string              // Qualified name of the body struct ("models.User"), if known
+	BodyEnums      []EnumField         // Fields of the body type limited to the constants of their type
+	Query          []QueryParam        // Query parameters read by the handler
+	PathParams     map[string]ParamDoc // Documentation of path parameters by name (from -spec)
+	RequestHeaders []HeaderParam       // Headers and cookies read by the handler
+	Responses      []ResponseExample   // Responses written by the handler
+	Middleware     []string            // Middleware attached to the route or its groups
+	Auth           *AuthInfo           // Auth required by the middleware
+	Tags           []string            // @tag users
+	Type           string              // "REST", "GraphQL", "RPC"
+	GraphQL        *GraphQLInfo        // GraphQL specific information
 }
 
 type GraphQLInfo struct {
@@ -46,11 +58,54 @@ var (
 	queryRe     = regexp.MustCompile(`(?i)@query\s+(.+)$`)
 	variablesRe = regexp.MustCompile(`(?i)@variables\s+(.+)$`)
 	restRe      = regexp.MustCompile(`(?i)@rest\s+([A-Z]+)\s+(\S+)(?:\s+(.*))?$`)
+	authRe      = regexp.MustCompile(`(?i)@auth\s+(\S+)$`)
 )
 
 // ScanDir: heuristic scanning (without type-checking)
 func ScanDir(root string) ([]Endpoint, error) {
+	return scanDir(root, nil)
+}
+
+func scanDir(root string, ex *exampleOptions) ([]Endpoint, error) {
 	fset := token.NewFileSet()
+
+	// Parse every file up front so routes can be resolved across files
+	var files []sourceFile
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "bin" || name == "dist" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		file, perr := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if perr != nil {
+			return fmt.Errorf("parse %s: %w", path, perr)
+		}
+		files = append(files, sourceFile{path: path, file: file})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return scanFiles(root, fset, files, nil, ex), nil
+}
+
+// scanFiles detects endpoints in already parsed files. A non-nil info must
+// hold complete type information (see ScanDirWithOpts): route receivers are
+// then confirmed against known router packages and handlers and body
+// variables are resolved through their real declarations. Examples are
+// built with ex (nil for the defaults).
+func scanFiles(root string, fset *token.FileSet, files []sourceFile, info *types.Info, ex *exampleOptions) []Endpoint {
 	var endpoints []Endpoint
 	seen := make(map[string]struct{})
 
@@ -65,7 +120,14 @@ func ScanDir(root string) ([]Endpoint, error) {
 		if e.Type == "" {
 			e.Type = "REST"
 		}
-		key := strings.ToUpper(e.Method) + " " + e.Path + " " + e.SourceFile + " " + strings.Join(e.Tags, ",")
+		// The body type only describes a body that was kept
+		if e.BodyRaw == "" {
+			e.BodyType = ""
+		}
+		if e.BodyType != "" && e.BodyEnums == nil {
+			e.BodyEnums = bodyEnums(e.BodyType)
+		}
+		key := strings.ToUpper(e.Method) + " " + e.Host + e.Path + " " + e.SourceFile + " " + strings.Join(e.Tags, ",")
 		if _, ok := seen[key]; ok {
 			return
 		}
@@ -84,64 +146,24 @@ func ScanDir(root string) ([]Endpoint, error) {
 		globalProjectAnalysis = projectAnalysis
 	}
 
-	// Global function bodies map to store all detected bodies across files
-	globalFunctionBodies := make(map[string]string)
+	// Typed body detection reads the real type of decode targets
+	globalTypesInfo = info
+	defer func() { globalTypesInfo = nil }()
 
-	// First pass: collect all function bodies
-	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "bin" || name == "dist" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
-
-		file, perr := parser.ParseFile(fset, path, nil, parser.ParseComments)
-		if perr != nil {
-			return fmt.Errorf("parse %s: %w", path, perr)
-		}
-
-		// Collect function bodies from this file
-		fileFunctionBodies := scanFunctionsForBodies(file, fset)
-		for funcName, body := range fileFunctionBodies {
-			globalFunctionBodies[funcName] = body
-		}
-
-		return nil
-	})
-	if err != nil {
-		return nil, err
+	// First pass: collect what every function reads from the request
+	handlers := newHandlerIndex(info, fset, ex)
+	for _, sf := range files {
+		handlers.collect(sf.file)
 	}
 
-	// Second pass: scan for endpoints and use global function bodies
-	err = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
-		if d.IsDir() {
-			name := d.Name()
-			if strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules" || name == "bin" || name == "dist" {
-				return filepath.SkipDir
-			}
-			return nil
-		}
-		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
-			return nil
-		}
+	// Router groups: resolve the prefix each router variable carries
+	routes := newRouteIndex(fset, files, info)
 
-		file, perr := parser.ParseFile(fset, path, nil, parser.ParseComments)
-		if perr != nil {
-			return fmt.Errorf("parse %s: %w", path, perr)
-		}
+	// Second pass: scan for endpoints and use global function bodies
+	for _, sf := range files {
+		file, path := sf.file, sf.path
 
-		anns, _ := scanAnnotationsFromFile(file, path)
+		anns, _ := scanAnnotationsFromFile(file, path, fset)
 		for _, a := range anns {
 			add(a)
 		}
@@ -156,17 +178,37 @@ func ScanDir(root string) ([]Endpoint, error) {
 			case *ast.SelectorExpr:
 				sel := fun.Sel.Name
 
+				// With type information, only calls into router packages count
+				if info != nil && !isRouterSelector(info, fun) {
+					return true
+				}
+
+				pos := fset.Position(call.Pos())
+				// Only routes with per-route middleware depend on the router
+				echo := len(call.Args) > 2 && routes.isEcho(fun.X)
+
+				// Middleware from Use, With and Group applies to every route
+				// registered on the receiver
+				addRoute := func(recv ast.Expr, route *ast.CallExpr, e Endpoint) {
+					add(routes.routeMiddleware(recv, route, echo, handlers).apply(e))
+				}
+
 				// Special case: *.Methods("GET", "POST") chained from HandleFunc
 				if sel == "Methods" && len(call.Args) >= 1 {
 					if selExpr, ok := call.Fun.(*ast.SelectorExpr); ok {
 						if innerCall, ok := selExpr.X.(*ast.CallExpr); ok {
 							if innerSel, ok := innerCall.Fun.(*ast.SelectorExpr); ok {
 								if (innerSel.Sel.Name == "HandleFunc" || innerSel.Sel.Name == "Handle") && len(innerCall.Args) >= 1 {
-									if pathLit, ok := innerCall.Args[0].(*ast.BasicLit); ok && pathLit.Kind == token.STRING {
-										if p, err := strconv.Unquote(pathLit.Value); err == nil && isValidEndpointPath(p) {
+									if raw, ok := routes.routePath(innerCall.Args[0]); ok {
+										for _, p := range routes.paths(innerSel.X, raw) {
 											methods := stringArgs(call.Args)
+											details := handlers.lookup(innerCall, echo)
+											// Methods(...) routes are listed without a body example
+											details.body = ""
 											for _, m := range methods {
-												add(Endpoint{Method: m, Path: p, SourceFile: fset.Position(call.Pos()).Filename, Handler: guessHandlerName(innerCall), Headers: map[string]string{}, Type: "REST"})
+												e := endpointFrom(m, p, pos.Filename, pos.Line, details)
+												e.Handler = guessHandlerName(innerCall, echo)
+												addRoute(innerSel.X, innerCall, e)
 											}
 										}
 									}
@@ -178,39 +220,29 @@ func ScanDir(root string) ([]Endpoint, error) {
 
 				// chi-like: r.Get("/path", handler)
 				if isVerb(sel) && len(call.Args) >= 1 {
-					if pathLit, ok := call.Args[0].(*ast.BasicLit); ok && pathLit.Kind == token.STRING {
-						if p, err := strconv.Unquote(pathLit.Value); err == nil && isValidEndpointPath(p) {
-							handler := guessHandlerName(call)
-							body := ""
-							if handler != "" && globalFunctionBodies[handler] != "" {
-								body = globalFunctionBodies[handler]
-							}
-							add(Endpoint{
-								Method:     strings.ToUpper(sel),
-								Path:       p,
-								SourceFile: fset.Position(call.Pos()).Filename,
-								Handler:    handler,
-								Headers:    map[string]string{},
-								BodyRaw:    body,
-								Type:       "REST",
-							})
+					if raw, ok := routes.routePath(call.Args[0]); ok {
+						for _, p := range routes.paths(fun.X, raw) {
+							e := endpointFrom(strings.ToUpper(sel), p, pos.Filename, pos.Line, handlers.lookup(call, echo))
+							e.Handler = guessHandlerName(call, echo)
+							addRoute(fun.X, call, e)
 						}
 					}
 				}
 
 				// GraphQL endpoints detection (only for POST method)
 				if sel == "POST" && len(call.Args) >= 1 {
-					if pathLit, ok := call.Args[0].(*ast.BasicLit); ok && pathLit.Kind == token.STRING {
-						if p, err := strconv.Unquote(pathLit.Value); err == nil && isValidEndpointPath(p) {
+					if raw, ok := routes.routePath(call.Args[0]); ok {
+						for _, p := range routes.paths(fun.X, raw) {
 							// Common GraphQL endpoint patterns
 							if strings.Contains(strings.ToLower(p), "graphql") ||
 								strings.Contains(strings.ToLower(p), "graph") ||
 								strings.HasSuffix(strings.ToLower(p), "/query") {
-								add(Endpoint{
+								addRoute(fun.X, call, Endpoint{
 									Method:     "POST",
 									Path:       p,
-									SourceFile: fset.Position(call.Pos()).Filename,
-									Handler:    guessHandlerName(call),
+									SourceFile: pos.Filename,
+									Line:       pos.Line,
+									Handler:    guessHandlerName(call, echo),
 									Headers:    map[string]string{},
 									Type:       "GraphQL",
 									GraphQL: &GraphQLInfo{
@@ -218,20 +250,9 @@ func ScanDir(root string) ([]Endpoint, error) {
 									},
 								})
 							} else {
-								handler := guessHandlerName(call)
-								body := ""
-								if handler != "" && globalFunctionBodies[handler] != "" {
-									body = globalFunctionBodies[handler]
-								}
-								add(Endpoint{
-									Method:     "POST",
-									Path:       p,
-									SourceFile: fset.Position(call.Pos()).Filename,
-									Handler:    handler,
-									Headers:    map[string]string{},
-									BodyRaw:    body,
-									Type:       "REST",
-								})
+								e := endpointFrom("POST", p, pos.Filename, pos.Line, handlers.lookup(call, echo))
+								e.Handler = guessHandlerName(call, echo)
+								addRoute(fun.X, call, e)
 							}
 						}
 					}
@@ -239,24 +260,29 @@ func ScanDir(root string) ([]Endpoint, error) {
 
 				// net/http & gorilla: *.HandleFunc("/path", h)
 				if sel == "HandleFunc" && len(call.Args) >= 1 {
-					if pathLit, ok := call.Args[0].(*ast.BasicLit); ok && pathLit.Kind == token.STRING {
-						if p, err := strconv.Unquote(pathLit.Value); err == nil && isValidEndpointPath(p) {
+					if raw, ok := routes.routePath(call.Args[0]); ok {
+						// Go 1.22 patterns may carry the method and host: "GET example.com/items/{id}"
+						method, host, pattern := parseServeMuxPattern(raw)
+						for _, p := range routes.paths(fun.X, pattern) {
 							methods := findChainedMethods(n)
-							handler := guessHandlerName(call)
-							body := ""
-							if handler != "" && globalFunctionBodies[handler] != "" {
-								body = globalFunctionBodies[handler]
+							if method != "" {
+								methods = []string{method}
 							}
+							handler := guessHandlerName(call, echo)
+							details := handlers.lookup(call, echo)
 							if len(methods) == 0 {
-								add(Endpoint{Method: "ANY", Path: p, SourceFile: fset.Position(call.Pos()).Filename, Handler: handler, Headers: map[string]string{}, BodyRaw: body, Type: "REST"})
+								e := endpointFrom("ANY", p, pos.Filename, pos.Line, details)
+								e.Host, e.Handler = host, handler
+								addRoute(fun.X, call, e)
 							} else {
 								for _, m := range methods {
+									e := endpointFrom(m, p, pos.Filename, pos.Line, details)
+									e.Host, e.Handler = host, handler
 									// Only add body for methods that typically use them
-									methodBody := ""
-									if (m == "POST" || m == "PUT" || m == "PATCH") && body != "" {
-										methodBody = body
+									if m != "POST" && m != "PUT" && m != "PATCH" {
+										e.BodyRaw = ""
 									}
-									add(Endpoint{Method: m, Path: p, SourceFile: fset.Position(call.Pos()).Filename, Handler: handler, Headers: map[string]string{}, BodyRaw: methodBody, Type: "REST"})
+									addRoute(fun.X, call, e)
 								}
 							}
 						}
@@ -265,24 +291,29 @@ func ScanDir(root string) ([]Endpoint, error) {
 
 				// *.Handle("/path", h)
 				if sel == "Handle" && len(call.Args) >= 1 {
-					if pathLit, ok := call.Args[0].(*ast.BasicLit); ok && pathLit.Kind == token.STRING {
-						if p, err := strconv.Unquote(pathLit.Value); err == nil && isValidEndpointPath(p) {
+					if raw, ok := routes.routePath(call.Args[0]); ok {
+						// Go 1.22 patterns may carry the method and host: "GET example.com/items/{id}"
+						method, host, pattern := parseServeMuxPattern(raw)
+						for _, p := range routes.paths(fun.X, pattern) {
 							methods := findChainedMethods(n)
-							handler := guessHandlerName(call)
-							body := ""
-							if handler != "" && globalFunctionBodies[handler] != "" {
-								body = globalFunctionBodies[handler]
+							if method != "" {
+								methods = []string{method}
 							}
+							handler := guessHandlerName(call, echo)
+							details := handlers.lookup(call, echo)
 							if len(methods) == 0 {
-								add(Endpoint{Method: "ANY", Path: p, SourceFile: fset.Position(call.Pos()).Filename, Handler: handler, Headers: map[string]string{}, BodyRaw: body, Type: "REST"})
+								e := endpointFrom("ANY", p, pos.Filename, pos.Line, details)
+								e.Host, e.Handler = host, handler
+								addRoute(fun.X, call, e)
 							} else {
 								for _, m := range methods {
+									e := endpointFrom(m, p, pos.Filename, pos.Line, details)
+									e.Host, e.Handler = host, handler
 									// Only add body for methods that typically use them
-									methodBody := ""
-									if (m == "POST" || m == "PUT" || m == "PATCH") && body != "" {
-										methodBody = body
+									if m != "POST" && m != "PUT" && m != "PATCH" {
+										e.BodyRaw = ""
 									}
-									add(Endpoint{Method: m, Path: p, SourceFile: fset.Position(call.Pos()).Filename, Handler: handler, Headers: map[string]string{}, BodyRaw: methodBody, Type: "REST"})
+									addRoute(fun.X, call, e)
 								}
 							}
 						}
@@ -291,14 +322,45 @@ func ScanDir(root string) ([]Endpoint, error) {
 			}
 			return true
 		})
+	}
+	return endpoints
+}
 
-		return nil
-	})
-	return endpoints, err
+// endpointFrom builds the REST endpoint a route call registers from what
+// was detected in its handler
+func endpointFrom(method, path, file string, line int, details handlerDetails) Endpoint {
+	return Endpoint{
+		Method:         method,
+		Path:           path,
+		SourceFile:     file,
+		Line:           line,
+		Doc:            details.doc,
+		Headers:        map[string]string{},
+		BodyRaw:        details.body,
+		BodyType:       details.bodyType,
+		Query:          details.query,
+		RequestHeaders: details.headers,
+		Responses:      details.responses,
+		Auth:           details.auth,
+		Type:           "REST",
+	}
+}
+
+// annotationLine returns the line of the comment in cg holding text
+func annotationLine(fset *token.FileSet, cg *ast.CommentGroup, text string) int {
+	if fset == nil {
+		return 0
+	}
+	for _, c := range cg.List {
+		if strings.Contains(c.Text, text) {
+			return fset.Position(c.Pos()).Line
+		}
+	}
+	return fset.Position(cg.Pos()).Line
 }
 
 // reading annotations
-func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, error) {
+func scanAnnotationsFromFile(file *ast.File, sourcePath string, fset *token.FileSet) ([]Endpoint, error) {
 	var res []Endpoint
 
 	for _, cg := range file.Comments {
@@ -313,7 +375,8 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 			// Check if line matches any annotation pattern
 			if headerRe.MatchString(line) || bodyRe.MatchString(line) || tagRe.MatchString(line) ||
 				schemaRe.MatchString(line) || queryRe.MatchString(line) || variablesRe.MatchString(line) ||
-				graphqlRe.MatchString(line) || restRe.MatchString(line) || routeRe.MatchString(line) {
+				graphqlRe.MatchString(line) || restRe.MatchString(line) || routeRe.MatchString(line) ||
+				authRe.MatchString(line) {
 				annotations = append(annotations, line)
 			}
 		}
@@ -322,6 +385,7 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 		var routes []struct {
 			method, path, desc, routeType string
 			operation                     string // for GraphQL
+			line                          int
 		}
 
 		// Find all route definitions first
@@ -336,7 +400,8 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 				routes = append(routes, struct {
 					method, path, desc, routeType string
 					operation                     string
-				}{"POST", path, desc, "GraphQL", operation})
+					line                          int
+				}{"POST", path, desc, "GraphQL", operation, annotationLine(fset, cg, line)})
 			}
 			if m := restRe.FindStringSubmatch(line); len(m) > 0 {
 				method := strings.ToUpper(m[1])
@@ -351,7 +416,8 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 				routes = append(routes, struct {
 					method, path, desc, routeType string
 					operation                     string
-				}{method, path, desc, "REST", ""})
+					line                          int
+				}{method, path, desc, "REST", "", annotationLine(fset, cg, line)})
 			}
 			if m := routeRe.FindStringSubmatch(line); len(m) > 0 {
 				method := strings.ToUpper(m[1])
@@ -366,7 +432,8 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 				routes = append(routes, struct {
 					method, path, desc, routeType string
 					operation                     string
-				}{method, path, desc, "REST", ""})
+					line                          int
+				}{method, path, desc, "REST", "", annotationLine(fset, cg, line)})
 			}
 		}
 
@@ -375,6 +442,7 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 		accBody := ""
 		var accTags []string
 		var accGraphQL *GraphQLInfo
+		var accAuth *AuthInfo
 
 		for _, line := range annotations {
 			// Headers
@@ -393,6 +461,12 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 				continue
 			}
 
+			// Auth
+			if m := authRe.FindStringSubmatch(line); len(m) > 0 {
+				accAuth = annotationAuth(m[1])
+				continue
+			}
+
 			// Tags
 			if m := tagRe.FindStringSubmatch(line); len(m) > 0 {
 				tag := strings.TrimSpace(m[1])
@@ -450,10 +524,12 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 					Method:     route.method,
 					Path:       route.path,
 					SourceFile: sourcePath,
+					Line:       route.line,
 					Desc:       route.desc,
 					Headers:    hcopy,
 					BodyRaw:    accBody,
 					Tags:       tcopy,
+					Auth:       accAuth,
 					Type:       "GraphQL",
 					GraphQL:    accGraphQL,
 				})
@@ -462,10 +538,12 @@ func scanAnnotationsFromFile(file *ast.File, sourcePath string) ([]Endpoint, err
 					Method:     route.method,
 					Path:       route.path,
 					SourceFile: sourcePath,
+					Line:       route.line,
 					Desc:       route.desc,
 					Headers:    hcopy,
 					BodyRaw:    accBody,
 					Tags:       tcopy,
+					Auth:       accAuth,
 					Type:       "REST",
 					GraphQL:    nil,
 				})
@@ -480,18 +558,41 @@ func isVerb(s string) bool {
 	return ok
 }
 
-func guessHandlerName(call *ast.CallExpr) string {
-	if len(call.Args) >= 2 {
-		switch a := call.Args[1].(type) {
-		case *ast.Ident:
-			return a.Name
-		case *ast.SelectorExpr:
-			return a.Sel.Name
-		}
+func guessHandlerName(call *ast.CallExpr, echo bool) string {
+	switch a := routeHandler(call, echo).(type) {
+	case *ast.Ident:
+		return a.Name
+	case *ast.SelectorExpr:
+		return a.Sel.Name
 	}
 	return ""
 }
 
+// routeHandler returns the handler of a route call: the argument after
+// the path for echo, which takes per-route middleware after it, and the
+// last argument for gin, chi and fiber, which take middleware before it
+func routeHandler(call *ast.CallExpr, echo bool) ast.Expr {
+	if len(call.Args) < 2 {
+		return nil
+	}
+	if echo {
+		return call.Args[1]
+	}
+	return call.Args[len(call.Args)-1]
+}
+
+// routeMiddlewareArgs returns the per-route middleware of a route call,
+// the arguments other than the path and handler
+func routeMiddlewareArgs(call *ast.CallExpr, echo bool) []ast.Expr {
+	if len(call.Args) < 3 {
+		return nil
+	}
+	if echo {
+		return call.Args[2:]
+	}
+	return call.Args[1 : len(call.Args)-1]
+}
+
 func findChainedMethods(n ast.Node) []string {
 	call, ok := n.(*ast.CallExpr)
 	if !ok {
@@ -562,6 +663,33 @@ func isHeaderLikeFormat(s string) bool {
 	return validParts >= 2 // Must have at least 2 valid parts
 }
 
+// parseServeMuxPattern splits a Go 1.22 net/http ServeMux pattern
+// "[METHOD ][HOST]/[PATH]" into its parts. Wildcards ({name}, {name...}) are
+// kept as is; the {$} anchor only pins the trailing slash and is dropped.
+// Plain "/path" patterns come back unchanged with an empty method and host.
+func parseServeMuxPattern(pattern string) (method, host, path string) {
+	path = strings.TrimSpace(pattern)
+	if i := strings.IndexAny(path, " \t"); i > 0 && isMethodToken(path[:i]) {
+		method = path[:i]
+		path = strings.TrimLeft(path[i:], " \t")
+	}
+	if i := strings.Index(path, "/"); i > 0 {
+		host, path = path[:i], path[i:]
+	}
+	path = strings.TrimSuffix(path, "{$}")
+	return method, host, path
+}
+
+// isMethodToken reports whether s looks like an HTTP method (GET, PROPFIND...)
+func isMethodToken(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return s != ""
+}
+
 // isValidEndpointPath validates if a string is a valid HTTP endpoint path
 func isValidEndpointPath(path string) bool {
 	if path == "" {
@@ -604,23 +732,3 @@ func isValidEndpointPath(path string) bool {
 
 	return true
 }
-
-// scanFunctionsForBodies analyzes all functions in a file to detect JSON body usage
-func scanFunctionsForBodies(file *ast.File, fset *token.FileSet) map[string]string {
-	functionBodies := make(map[string]string)
-
-	// Iterate through all function declarations
-	for _, decl := range file.Decls {
-		if fn, ok := decl.(*ast.FuncDecl); ok {
-			if fn.Name != nil {
-				funcName := fn.Name.Name
-				detectedBody := DetectBodyFromFunction(fn, fset)
-				if detectedBody != "" {
-					functionBodies[funcName] = detectedBody
-				}
-			}
-		}
-	}
-
-	return functionBodies
-}
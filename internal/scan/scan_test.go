@@ -97,3 +97,151 @@ func main() {
 		}
 	}
 }
+
+func TestScanDir_ServeMuxPatterns(t *testing.T) {
+	dir := t.TempDir()
+	code := `
+package main
+
+import "net/http"
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", getItem)
+	mux.Handle("POST /items/{$}", createItem)
+	mux.HandleFunc("DELETE api.example.com/items/{path...}", deleteItems)
+	mux.HandleFunc("/legacy/ping", ping)
+}
+`
+	fp := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(fp, []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	want := map[string]string{
+		"GET /items/{id}":         "",
+		"POST /items/":            "",
+		"DELETE /items/{path...}": "api.example.com",
+		"ANY /legacy/ping":        "",
+	}
+	got := make(map[string]string)
+	for _, e := range eps {
+		got[e.Method+" "+e.Path] = e.Host
+	}
+	for k, host := range want {
+		gotHost, ok := got[k]
+		if !ok {
+			t.Errorf("missing %s (got %v)", k, got)
+			continue
+		}
+		if gotHost != host {
+			t.Errorf("%s: expected host %q, got %q", k, host, gotHost)
+		}
+	}
+}
+
+func TestScanDir_SourceLineAndHandlerDoc(t *testing.T) {
+	dir := t.TempDir()
+	code := `package main
+
+import "net/http"
+
+// listUsers returns every user.
+//
+// @header X-Trace: 1
+func listUsers(w http.ResponseWriter, r *http.Request) {}
+
+// @route GET /v1/report Monthly report
+func report() {}
+
+func main() {
+	var c Router
+	c.Get("/v1/users", listUsers)
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	byPath := make(map[string]Endpoint)
+	for _, e := range eps {
+		byPath[e.Path] = e
+	}
+	if users := byPath["/v1/users"]; users.Line != 15 || users.Doc != "listUsers returns every user." {
+		t.Errorf("unexpected line/doc for /v1/users: %d %q", users.Line, users.Doc)
+	}
+	if report := byPath["/v1/report"]; report.Line != 10 || report.Desc != "Monthly report" {
+		t.Errorf("unexpected line/desc for /v1/report: %d %q", report.Line, report.Desc)
+	}
+}
+
+func TestScanDir_SameNameMethodsInOtherPackages(t *testing.T) {
+	prev := globalProjectAnalysis
+	t.Cleanup(func() { globalProjectAnalysis = prev })
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go": `package main
+
+import "github.com/gin-gonic/gin"
+
+func main() {
+	r := gin.Default()
+	h := handler.UserHandler{}
+	r.POST("/users", h.Create)
+}
+`,
+		"handler/user.go": `package handler
+
+import "github.com/gin-gonic/gin"
+
+type UserHandler struct{}
+
+func (h *UserHandler) Create(c *gin.Context) {
+	var req struct {
+		Name  string ` + "`json:\"name\"`" + `
+		Email string ` + "`json:\"email\"`" + `
+	}
+	c.ShouldBindJSON(&req)
+}
+`,
+		"service/user.go": `package service
+
+type UserService struct{}
+
+// Create stores a user.
+func (s *UserService) Create(name string) error { return nil }
+`,
+	}
+	for name, code := range files {
+		fp := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fp), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(fp, []byte(code), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+
+	eps, err := ScanDir(dir)
+	if err != nil {
+		t.Fatalf("ScanDir err: %v", err)
+	}
+	if len(eps) != 1 {
+		t.Fatalf("expected 1 endpoint, got %d", len(eps))
+	}
+	if !strings.Contains(eps[0].BodyRaw, `"name"`) || !strings.Contains(eps[0].BodyRaw, `"email"`) {
+		t.Errorf("expected the handler's body, got %q", eps[0].BodyRaw)
+	}
+	// nothing is taken from the service method of the same name
+	if eps[0].Doc != "" {
+		t.Errorf("expected no doc from UserService.Create, got %q", eps[0].Doc)
+	}
+}
@@ -207,7 +207,7 @@ struct {
 		"Age":    {"age", "int"},
 		"Active": {"is_active", "bool"},
 		"Tags":   {"tags", "[]string"},
-		"NoTag":  {"notag", "string"}, // Should use lowercase field name
+		"NoTag":  {"NoTag", "string"}, // encoding/json keeps the Go field name
 	}
 
 	if len(info.Fields) != len(expectedFields) {
@@ -272,6 +272,10 @@ func TestExtractJSONTag(t *testing.T) {
 		{`json:"name,omitempty"`, "name"},
 		{`json:",omitempty"`, ""},
 		{`json:"-"`, ""},
+		{`json:"-,"`, "-"},
+		{`json:"first name"`, "first name"},
+		{`json:"id,string"`, "id"},
+		{`json:"a\\b"`, ""},
 		{`json:"name" validate:"required"`, "name"},
 		{`validate:"required" json:"name"`, "name"},
 		{`xml:"name"`, ""},
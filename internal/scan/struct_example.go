@@ -0,0 +1,320 @@
+package scan
+
+import (
+	"go/ast"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxExampleDepth bounds how deep nested structs are expanded in examples
+const maxExampleDepth = 8
+
+// exampleBuilder expands a struct into example JSON the way encoding/json
+// encodes it: nested structs are expanded, pointers dereferenced and the
+// fields of embedded structs promoted
+type exampleBuilder struct {
+	visiting map[string]bool // structs being expanded, to stop at cycles
+	ex       *exampleOptions
+}
+
+// exampleOptions is the state of one scan that examples are built with;
+// the zero value (or nil) builds full examples and reports nothing
+type exampleOptions struct {
+	minimal bool             // leave out omitempty and omitzero fields
+	report  func(Diagnostic) // receives opaque types (optional)
+	seen    map[string]bool  // opaque types already reported
+}
+
+// exampleField is a field of the encoded object before name conflicts
+// between promoted fields are resolved
+type exampleField struct {
+	name   string
+	value  string
+	depth  int  // embedding depth
+	tagged bool // named by a json tag
+}
+
+// generateJSONFromProjectStruct generates JSON from project-analyzed struct
+func generateJSONFromProjectStruct(structDef *StructDefinition, ex *exampleOptions) string {
+	if ex == nil {
+		ex = &exampleOptions{}
+	}
+	b := &exampleBuilder{visiting: make(map[string]bool), ex: ex}
+	return b.structJSON(structDef, 0)
+}
+
+func (b *exampleBuilder) structJSON(def *StructDefinition, depth int) string {
+	if def == nil {
+		return "{}"
+	}
+	if key := def.QualifiedName(); key != "" {
+		b.visiting[key] = true
+		defer delete(b.visiting, key)
+	}
+
+	var fields []exampleField
+	b.collectFields(def, depth, 0, &fields)
+
+	// Among fields with the same name the shallowest wins; at the same
+	// depth a tagged field wins, otherwise none is encoded
+	var pairs []string
+	for i, f := range fields {
+		winner, conflict := i, false
+		for j, other := range fields {
+			if j == i || other.name != f.name {
+				continue
+			}
+			switch w := fields[winner]; {
+			case other.depth < w.depth, other.depth == w.depth && other.tagged && !w.tagged:
+				winner, conflict = j, false
+			case other.depth == w.depth && other.tagged == w.tagged:
+				conflict = true
+			}
+		}
+		if winner == i && !conflict {
+			pairs = append(pairs, jsonString(f.name)+":"+f.value)
+		}
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// collectFields lists the encoded fields of def, promoting those of
+// embedded structs. Like encoding/json it skips unexported and "-" fields
+// and keeps untagged names as they are.
+func (b *exampleBuilder) collectFields(def *StructDefinition, depth, embedDepth int, out *[]exampleField) {
+	for _, f := range def.Fields {
+		tag := parseJSONTag(f.Tag)
+		if tag.Skip || (f.JSONTag == "-" && tag.Name != "-") {
+			continue // Skip fields marked as ignored; json:"-," names a field "-"
+		}
+		if b.ex.minimal && (tag.OmitEmpty || tag.OmitZero) {
+			continue
+		}
+		if f.Embedded {
+			typeName := strings.TrimLeft(f.Type, "*")
+			embedded := lookupFieldStruct(typeName, def.Package)
+			if embedded == nil && !ast.IsExported(f.Name) {
+				continue // unexported embedded non-struct types are not encoded
+			}
+			if embedded != nil && tag.Name == "" {
+				key := embedded.QualifiedName()
+				if embedDepth < maxExampleDepth && !b.visiting[key] {
+					if key != "" {
+						b.visiting[key] = true
+					}
+					b.collectFields(embedded, depth, embedDepth+1, out)
+					delete(b.visiting, key)
+				}
+				continue
+			}
+			if embedded == nil {
+				if _, _, ok := lookupFieldType(typeName, def.Package); !ok {
+					continue // most likely a struct from another module, fields unknown
+				}
+			}
+		} else if !ast.IsExported(f.Name) {
+			continue
+		}
+
+		value := b.fieldValue(f.Type, def.Package, depth+1, ParseFieldRules(f.Tag))
+		if kind := basicKind(f.Type, def.Package); tag.String && kind != "" {
+			// encoding/json only quotes strings, numbers and bools
+			value = jsonString(quotedNumber(value, kind))
+		}
+		*out = append(*out, exampleField{
+			name:   jsonFieldName(f.Name, f.Tag),
+			value:  value,
+			depth:  embedDepth,
+			tagged: tag.Name != "",
+		})
+	}
+}
+
+// fieldValue builds the example for a field that passes its validator
+// rules
+func (b *exampleBuilder) fieldValue(goType, pkg string, depth int, rules FieldRules) string {
+	if rules.IsZero() {
+		return b.value(goType, pkg, depth)
+	}
+	if kind := basicKind(goType, pkg); kind != "" {
+		return rules.withEnum(enumValues(goType, pkg), kind).scalarExample(kind)
+	}
+
+	elem, isSlice := strings.CutPrefix(strings.TrimLeft(goType, "*"), "[]")
+	if !isSlice {
+		return b.value(goType, pkg, depth)
+	}
+	if _, ok := wellKnown("[]"+elem, pkg); ok {
+		return b.value(goType, pkg, depth)
+	}
+	count, bounded := rules.length(1)
+	if !bounded && rules.Dive == nil {
+		return b.value(goType, pkg, depth)
+	}
+	if count == 0 {
+		return "[]"
+	}
+	item := b.value(elem, pkg, depth+1)
+	if rules.Dive != nil {
+		item = b.fieldValue(elem, pkg, depth+1, *rules.Dive)
+	}
+	if lookupFieldStruct(strings.TrimLeft(elem, "*"), pkg) != nil {
+		// the whole slice tells whether the element can be expanded
+		if v := b.value(goType, pkg, depth); v == "[]" {
+			return v
+		}
+	}
+	items := make([]string, count)
+	for i := range items {
+		items[i] = item
+	}
+	return "[" + strings.Join(items, ",") + "]"
+}
+
+// value builds the example for a field type written in package pkg
+func (b *exampleBuilder) value(goType, pkg string, depth int) string {
+	pointer := strings.HasPrefix(goType, "*")
+	goType = strings.TrimLeft(goType, "*")
+
+	if wk, ok := wellKnown(goType, pkg); ok {
+		return wk.Example
+	}
+	if key, fn := marshalerMethod(goType, pkg); fn != nil {
+		// the fields don't tell what MarshalJSON writes; text is a string
+		if fn.Name == marshalJSON {
+			b.ex.reportOpaque(key, fn)
+		}
+		return `"string"`
+	}
+
+	switch {
+	case strings.HasPrefix(goType, "[]"):
+		elem := goType[2:]
+		if def := lookupFieldStruct(strings.TrimLeft(elem, "*"), pkg); def != nil && !b.expandable(def, depth) {
+			return "[]"
+		}
+		return "[" + b.value(elem, pkg, depth+1) + "]"
+	case strings.HasPrefix(goType, "map["):
+		elem := mapValueType(goType)
+		if def := lookupFieldStruct(strings.TrimLeft(elem, "*"), pkg); def != nil && b.expandable(def, depth) {
+			return `{"key":` + b.value(elem, pkg, depth+1) + "}"
+		}
+		return "{}"
+	}
+
+	if def := lookupFieldStruct(goType, pkg); def != nil {
+		if !b.expandable(def, depth) {
+			if pointer {
+				return "null"
+			}
+			return "{}"
+		}
+		return b.structJSON(def, depth)
+	}
+	if values := enumValues(goType, pkg); len(values) > 0 {
+		return values[0].Value
+	}
+	if underlying, typePkg, ok := lookupFieldType(goType, pkg); ok && depth <= maxExampleDepth {
+		return b.value(underlying, typePkg, depth+1)
+	}
+	return generateValueForType(goType)
+}
+
+// expandable reports whether def can be expanded at depth without
+// recursing into itself
+func (b *exampleBuilder) expandable(def *StructDefinition, depth int) bool {
+	return depth <= maxExampleDepth && !b.visiting[def.QualifiedName()]
+}
+
+// lookupFieldStruct resolves a struct named in a field of package pkg:
+// "Customer" in the same package or "models.Customer" in another one.
+// Qualifiers that are not package names (import aliases) and fields of
+// structs without a package fall back to a lookup by bare name.
+func lookupFieldStruct(typeName, pkg string) *StructDefinition {
+	if globalProjectAnalysis == nil || typeName == "" {
+		return nil
+	}
+	if qualifier, _, ok := strings.Cut(typeName, "."); ok {
+		if def, ok := globalProjectAnalysis.Structs[typeName]; ok {
+			return def
+		}
+		if _, known := globalProjectAnalysis.Packages[qualifier]; known {
+			return nil
+		}
+		return lookupProjectStruct(typeName)
+	}
+	if pkg == "" {
+		return lookupProjectStruct(typeName)
+	}
+	return globalProjectAnalysis.Structs[pkg+"."+typeName]
+}
+
+// lookupFieldType resolves a named non-struct type (type Status string)
+// to its underlying type and the package that type is written in
+func lookupFieldType(typeName, pkg string) (underlying, typePkg string, ok bool) {
+	if globalProjectAnalysis == nil || typeName == "" {
+		return "", "", false
+	}
+	key := typeName
+	if !strings.Contains(typeName, ".") {
+		if pkg == "" {
+			return "", "", false
+		}
+		key = pkg + "." + typeName
+	}
+	def, ok := globalProjectAnalysis.Types[key]
+	if !ok {
+		return "", "", false
+	}
+	return def.UnderlyingType, def.Package, true
+}
+
+// mapValueType returns V for map[K]V, honoring nested brackets in K
+func mapValueType(goType string) string {
+	depth := 0
+	for i, r := range goType {
+		switch r {
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return goType[i+1:]
+			}
+		}
+	}
+	return "string"
+}
+
+// embeddedName is the field name Go gives an embedded type: Audit for
+// *models.Audit
+func embeddedName(typeName string) string {
+	typeName = strings.TrimLeft(typeName, "*")
+	if i := strings.LastIndex(typeName, "."); i >= 0 {
+		return typeName[i+1:]
+	}
+	return typeName
+}
+
+// quotedNumber writes a float example the way encoding/json writes it
+// before quoting it for the ,string option: 0.0 is "0"
+func quotedNumber(value, kind string) string {
+	bits := 64
+	switch kind {
+	case "float32":
+		bits = 32
+	case "float64":
+	default:
+		return value
+	}
+	f, err := strconv.ParseFloat(value, bits)
+	if err != nil {
+		return value
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	return strconv.FormatFloat(f, format, -1, bits)
+}
@@ -0,0 +1,240 @@
+package scan
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGenerateJSONFromProjectStruct_NestedTypes(t *testing.T) {
+	files := map[string]string{
+		"models/models.go": `package models
+
+type Customer struct {
+	Name    string  ` + "`json:\"name\"`" + `
+	Address Address ` + "`json:\"address\"`" + `
+}
+
+type Address struct {
+	City string ` + "`json:\"city\"`" + `
+}
+
+type Audit struct {
+	CreatedBy string ` + "`json:\"created_by\"`" + `
+	Note      string ` + "`json:\"note\"`" + `
+}
+`,
+		"main.go": `package main
+
+import "example.com/app/models"
+
+type Quantity int
+
+type LineItem struct {
+	SKU string   ` + "`json:\"sku\"`" + `
+	Qty Quantity ` + "`json:\"qty\"`" + `
+}
+
+type Meta struct {
+	Note string ` + "`json:\"note\"`" + `
+}
+
+type Category struct {
+	Name     string     ` + "`json:\"name\"`" + `
+	Parent   *Category  ` + "`json:\"parent\"`" + `
+	Children []Category ` + "`json:\"children\"`" + `
+}
+
+type CreateOrderRequest struct {
+	Customer models.Customer     ` + "`json:\"customer\"`" + `
+	Items    []LineItem          ` + "`json:\"items\"`" + `
+	ByCode   map[string]LineItem ` + "`json:\"by_code\"`" + `
+	Category *Category           ` + "`json:\"category\"`" + `
+	*models.Audit
+	Meta
+}
+`,
+	}
+	analysis := analyzeTestProject(t, files)
+
+	got := generateJSONFromProjectStruct(analysis.Structs["main.CreateOrderRequest"], nil)
+	// note is promoted from both Audit and Meta at the same depth, so
+	// encoding/json drops it
+	assertJSONEqual(t, got, `{
+		"customer": {"name": "string", "address": {"city": "string"}},
+		"items": [{"sku": "string", "qty": 0}],
+		"by_code": {"key": {"sku": "string", "qty": 0}},
+		"category": {"name": "string", "parent": null, "children": []},
+		"created_by": "string"
+	}`)
+}
+
+func TestGenerateJSONFromProjectStruct_WellKnownTypes(t *testing.T) {
+	analysis := analyzeTestProject(t, map[string]string{
+		"main.go": `package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+type Money struct {
+	Cents int64
+}
+
+func (m Money) MarshalJSON() ([]byte, error) { return nil, nil }
+
+type Level struct {
+	N int
+}
+
+func (l *Level) MarshalText() ([]byte, error) { return nil, nil }
+
+type Blob []byte
+
+type Payment struct {
+	ID       uuid.UUID       ` + "`json:\"id\"`" + `
+	PaidAt   *time.Time      ` + "`json:\"paid_at\"`" + `
+	Amount   decimal.Decimal ` + "`json:\"amount\"`" + `
+	Meta     json.RawMessage ` + "`json:\"meta\"`" + `
+	Receipt  Blob            ` + "`json:\"receipt\"`" + `
+	Note     sql.NullString  ` + "`json:\"note\"`" + `
+	Total    Money           ` + "`json:\"total\"`" + `
+	Fee      Money           ` + "`json:\"fee\"`" + `
+	Level    Level           ` + "`json:\"level\"`" + `
+	Currency Currency        ` + "`json:\"currency\"`" + `
+}
+
+type Currency struct {
+	Code string
+}
+`,
+	})
+
+	RegisterWellKnownType("main.Currency", WellKnownType{Example: `"EUR"`, Type: "string"})
+	defer delete(wellKnownTypes, "main.Currency")
+
+	var diags []Diagnostic
+	ex := &exampleOptions{report: func(d Diagnostic) { diags = append(diags, d) }}
+
+	got := generateJSONFromProjectStruct(analysis.Structs["main.Payment"], ex)
+	assertJSONEqual(t, got, `{
+		"id": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		"paid_at": "2024-01-01T00:00:00Z",
+		"amount": "10.50",
+		"meta": {},
+		"receipt": "aGVsbG8gd29ybGQ=",
+		"note": {"String": "string", "Valid": true},
+		"total": "string",
+		"fee": "string",
+		"level": "string",
+		"currency": "EUR"
+	}`)
+	if len(diags) != 1 || !strings.Contains(diags[0].Message, "main.Money implements json.Marshaler") {
+		t.Errorf("diagnostics = %v", diags)
+	}
+}
+
+// analyzeTestProject writes files to a temporary project, analyzes it and
+// installs the analysis for the duration of the test
+func analyzeTestProject(t *testing.T, files map[string]string) *ProjectAnalysis {
+	t.Helper()
+	dir := t.TempDir()
+	for name, code := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(code), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	analysis, err := AnalyzeProject(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := globalProjectAnalysis
+	globalProjectAnalysis = analysis
+	t.Cleanup(func() { globalProjectAnalysis = prev })
+	return analysis
+}
+
+func assertJSONEqual(t *testing.T, got, want string) {
+	t.Helper()
+	var decoded, expected any
+	if err := json.Unmarshal([]byte(got), &decoded); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(want), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("unexpected body %s", got)
+	}
+}
+
+func TestGenerateJSONFromProjectStruct_EncodingJSONRules(t *testing.T) {
+	analysis := analyzeTestProject(t, map[string]string{
+		"main.go": `package main
+
+type Count int
+
+type base struct {
+	Version int
+}
+
+type label string
+
+type Order struct {
+	ID       int64  ` + "`json:\"id,string\"`" + `
+	Name     string ` + "`json:\"name,string\"`" + `
+	Qty      *Count ` + "`json:\"qty,string,omitempty\"`" + `
+	Tags     []int  ` + "`json:\"tags,string\"`" + `
+	Price    float64 ` + "`json:\"price,string\"`" + `
+	Rating   float32 ` + "`json:\"rating,string\" validate:\"gt=4,lte=5\"`" + `
+	Note     string ` + "`json:\",omitempty\"`" + `
+	Internal string ` + "`json:\"-\"`" + `
+	Dash     string ` + "`json:\"-,\"`" + `
+	Created  string ` + "`json:\"created,omitzero\"`" + `
+	Untagged bool
+	secret   string
+	base
+	label
+}
+`,
+	})
+	def := analysis.Structs["main.Order"]
+
+	assertJSONEqual(t, generateJSONFromProjectStruct(def, nil), `{
+		"id": "0",
+		"name": "\"string\"",
+		"qty": "0",
+		"tags": [0],
+		"price": "0",
+		"rating": "4.5",
+		"Note": "string",
+		"-": "string",
+		"created": "string",
+		"Untagged": false,
+		"Version": 0
+	}`)
+
+	assertJSONEqual(t, generateJSONFromProjectStruct(def, &exampleOptions{minimal: true}), `{
+		"id": "0",
+		"name": "\"string\"",
+		"tags": [0],
+		"price": "0",
+		"rating": "4.5",
+		"-": "string",
+		"Untagged": false,
+		"Version": 0
+	}`)
+}
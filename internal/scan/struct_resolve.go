@@ -0,0 +1,144 @@
+package scan
+
+import (
+	"go/ast"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// resolveTargetStruct finds the struct a handler decodes or binds into,
+// e.g. the type of filter in c.ShouldBindQuery(&filter). Type information is
+// used when available; otherwise the variable's declaration in fn is looked
+// up and matched against the project analysis.
+func resolveTargetStruct(fn *ast.FuncDecl, expr ast.Expr) *StructDefinition {
+	for {
+		switch e := expr.(type) {
+		case *ast.UnaryExpr:
+			expr = e.X
+			continue
+		case *ast.ParenExpr:
+			expr = e.X
+			continue
+		}
+		break
+	}
+
+	if globalTypesInfo != nil {
+		if t := globalTypesInfo.TypeOf(expr); t != nil {
+			return structFromType(t)
+		}
+	}
+
+	ident, ok := expr.(*ast.Ident)
+	if !ok || fn.Body == nil {
+		return nil
+	}
+	typeExpr := localVarType(fn, ident.Name)
+	if typeExpr == nil {
+		return nil
+	}
+	if st, ok := typeExpr.(*ast.StructType); ok {
+		info := analyzeInlineStruct(st, "InlineStruct")
+		return &StructDefinition{Name: info.Name, Fields: info.Fields, Tags: make(map[string]string)}
+	}
+	return lookupProjectStruct(strings.TrimPrefix(getTypeString(typeExpr), "*"))
+}
+
+// structFromType converts a checked type into a struct definition,
+// preferring the project analysis entry for named structs
+func structFromType(t types.Type) *StructDefinition {
+	for {
+		ptr, ok := t.(*types.Pointer)
+		if !ok {
+			break
+		}
+		t = ptr.Elem()
+	}
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return nil
+	}
+	if named, ok := t.(*types.Named); ok && globalProjectAnalysis != nil && named.Obj().Pkg() != nil {
+		key := named.Obj().Pkg().Name() + "." + named.Obj().Name()
+		if structDef, exists := globalProjectAnalysis.Structs[key]; exists {
+			return structDef
+		}
+	}
+	return structDefinitionFromTypes(st)
+}
+
+// localVarType returns the declared type of a local variable: var x T,
+// x := T{} or x := &T{}
+func localVarType(fn *ast.FuncDecl, name string) ast.Expr {
+	var found ast.Expr
+	ast.Inspect(fn.Body, func(n ast.Node) bool {
+		if found != nil {
+			return false
+		}
+		switch node := n.(type) {
+		case *ast.ValueSpec:
+			for i, id := range node.Names {
+				if id.Name != name {
+					continue
+				}
+				if node.Type != nil {
+					found = node.Type
+				} else if i < len(node.Values) {
+					found = compositeType(node.Values[i])
+				}
+			}
+		case *ast.AssignStmt:
+			if len(node.Lhs) != len(node.Rhs) {
+				return true
+			}
+			for i, lhs := range node.Lhs {
+				if id, ok := lhs.(*ast.Ident); ok && id.Name == name {
+					found = compositeType(node.Rhs[i])
+				}
+			}
+		}
+		return true
+	})
+	return found
+}
+
+// compositeType returns T for T{...}, &T{...} and new(T)
+func compositeType(expr ast.Expr) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return e.Type
+	case *ast.UnaryExpr:
+		return compositeType(e.X)
+	case *ast.CallExpr:
+		if id, ok := e.Fun.(*ast.Ident); ok && id.Name == "new" && len(e.Args) == 1 {
+			return e.Args[0]
+		}
+	}
+	return nil
+}
+
+// lookupProjectStruct finds a struct by "pkg.Name" or bare name
+func lookupProjectStruct(typeName string) *StructDefinition {
+	if globalProjectAnalysis == nil || typeName == "" {
+		return nil
+	}
+	if structDef, ok := globalProjectAnalysis.Structs[typeName]; ok {
+		return structDef
+	}
+	name := typeName
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	keys := make([]string, 0, len(globalProjectAnalysis.Structs))
+	for key := range globalProjectAnalysis.Structs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if globalProjectAnalysis.Structs[key].Name == name {
+			return globalProjectAnalysis.Structs[key]
+		}
+	}
+	return nil
+}
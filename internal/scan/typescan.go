@@ -1,25 +1,199 @@
 package scan
 
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
 type ScanOptions struct {
-	Dir       string
-	UseTypes  bool
-	BuildTags string // build tags
+	Dir         string
+	UseTypes    bool
+	BuildTags   string           // build tags
+	Report      func(Diagnostic) // receives non-fatal problems (optional)
+	MinimalBody bool             // leave omitempty/omitzero fields out of examples
+}
+
+// Diagnostic is a non-fatal problem found while scanning
+type Diagnostic struct {
+	Pos     string // file:line:col when known
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Pos == "" {
+		return d.Message
+	}
+	return d.Pos + ": " + d.Message
+}
+
+// routerPackages are the import paths (without major version suffix) whose
+// methods register routes
+var routerPackages = map[string]struct{}{
+	"net/http":                            {},
+	"github.com/gin-gonic/gin":            {},
+	"github.com/go-chi/chi":               {},
+	"github.com/labstack/echo":            {},
+	"github.com/gorilla/mux":              {},
+	"github.com/gofiber/fiber":            {},
+	"github.com/julienschmidt/httprouter": {},
 }
 
 // ScanDirWithOpts: scans with go/packages+go/types when possible.
-// - Forces GOROOT in the analysis environment (avoids "errors without types").
-// - On ANY failure, falls back to simple local AST and does NOT return error.
+//   - Honors build tags when loading packages.
+//   - On ANY load or type-checking failure, reports a diagnostic and falls
+//     back to simple local AST scanning; it does NOT return an error.
+//   - Without UseTypes it is ScanDir with the options applied.
 func ScanDirWithOpts(opt ScanOptions) ([]Endpoint, error) {
-	// Temporarily always use ScanDir due to packages.Load issues
-	// TODO: Reactivate packages.Load when "package without types" issue is resolved
+	report := opt.Report
+	if report == nil {
+		report = func(Diagnostic) {}
+	}
+	ex := &exampleOptions{minimal: opt.MinimalBody, report: report}
+
 	if !opt.UseTypes {
-		eps, err := ScanDir(opt.Dir)
+		return scanDir(opt.Dir, ex)
+	}
+
+	// Typed analysis works on packages; a single file is scanned as is
+	if st, err := os.Stat(opt.Dir); err == nil && !st.IsDir() {
+		eps, err := scanDir(opt.Dir, ex)
 		return eps, nilOr(err)
 	}
 
-	// To avoid packages.Load errors, use direct fallback to ScanDir
-	eps, ferr := ScanDir(opt.Dir)
-	return eps, nilOr(ferr)
+	fset, files, info, diags := loadTyped(opt)
+	if len(diags) > 0 {
+		for _, d := range diags {
+			report(d)
+		}
+		report(Diagnostic{Message: "typed analysis unavailable, falling back to AST scanning"})
+		eps, ferr := scanDir(opt.Dir, ex)
+		return eps, nilOr(ferr)
+	}
+
+	return scanFiles(opt.Dir, fset, files, info, ex), nil
+}
+
+// loadTyped loads every package under opt.Dir with full type information.
+// Any problem is returned as diagnostics, in which case the result must not
+// be used.
+func loadTyped(opt ScanOptions) (*token.FileSet, []sourceFile, *types.Info, []Diagnostic) {
+	// NeedDeps type-checks dependencies from source: loading them from export
+	// data breaks whenever the toolchain is newer than x/tools ("package
+	// without types was imported from ...")
+	cfg := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedCompiledGoFiles |
+			packages.NeedImports | packages.NeedDeps | packages.NeedSyntax |
+			packages.NeedTypes | packages.NeedTypesInfo,
+		Dir:  opt.Dir,
+		Fset: token.NewFileSet(),
+	}
+	if opt.BuildTags != "" {
+		cfg.BuildFlags = []string{"-tags=" + opt.BuildTags}
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, nil, nil, []Diagnostic{{Message: fmt.Sprintf("load packages: %v", err)}}
+	}
+	if len(pkgs) == 0 {
+		return nil, nil, nil, []Diagnostic{{Message: "load packages: no Go packages found in " + opt.Dir}}
+	}
+
+	var diags []Diagnostic
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	var files []sourceFile
+	for _, pkg := range pkgs {
+		for _, e := range pkg.Errors {
+			diags = append(diags, Diagnostic{Pos: e.Pos, Message: e.Msg})
+		}
+		if pkg.TypesInfo == nil {
+			continue
+		}
+		for k, v := range pkg.TypesInfo.Types {
+			info.Types[k] = v
+		}
+		for k, v := range pkg.TypesInfo.Defs {
+			info.Defs[k] = v
+		}
+		for k, v := range pkg.TypesInfo.Uses {
+			info.Uses[k] = v
+		}
+		for k, v := range pkg.TypesInfo.Selections {
+			info.Selections[k] = v
+		}
+		for _, f := range pkg.Syntax {
+			name := cfg.Fset.Position(f.Pos()).Filename
+			if strings.HasSuffix(name, "_test.go") {
+				continue
+			}
+			files = append(files, sourceFile{path: name, file: f})
+		}
+	}
+	if len(diags) > 0 {
+		return nil, nil, nil, diags
+	}
+	return cfg.Fset, files, info, nil
+}
+
+// isRouterSelector reports whether a selector refers to a method or function
+// declared by a known router package
+func isRouterSelector(info *types.Info, sel *ast.SelectorExpr) bool {
+	var obj types.Object
+	if s, ok := info.Selections[sel]; ok {
+		obj = s.Obj()
+	} else {
+		obj = info.Uses[sel.Sel]
+	}
+	if obj == nil || obj.Pkg() == nil {
+		return false
+	}
+	_, ok := routerPackages[trimMajorVersion(obj.Pkg().Path())]
+	return ok
+}
+
+// trimMajorVersion strips a /vN module suffix from an import path
+func trimMajorVersion(importPath string) string {
+	base := path.Base(importPath)
+	if len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		return path.Dir(importPath)
+	}
+	return importPath
+}
+
+// handlerFunc resolves a handler expression to the function it refers to
+func handlerFunc(info *types.Info, expr ast.Expr) *types.Func {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return handlerFunc(info, e.X)
+	case *ast.Ident:
+		fn, _ := info.Uses[e].(*types.Func)
+		return fn
+	case *ast.SelectorExpr:
+		if sel, ok := info.Selections[e]; ok {
+			fn, _ := sel.Obj().(*types.Func)
+			return fn
+		}
+		fn, _ := info.Uses[e.Sel].(*types.Func)
+		return fn
+	case *ast.CallExpr:
+		// http.HandlerFunc(h) and similar conversions
+		if len(e.Args) == 1 {
+			return handlerFunc(info, e.Args[0])
+		}
+	}
+	return nil
 }
 
 // nilOr normalizes error to nil (helps maintain "no fatal error" API)
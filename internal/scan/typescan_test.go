@@ -0,0 +1,145 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTypedModule(t *testing.T, code string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/typed\n\ngo 1.22\n"), 0o644); err != nil {
+		t.Fatalf("write go.mod: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(code), 0o644); err != nil {
+		t.Fatalf("write main.go: %v", err)
+	}
+	return dir
+}
+
+func TestScanDirWithOpts_TypedReceiversAndBodies(t *testing.T) {
+	dir := writeTypedModule(t, `package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type cache struct{}
+
+func (cache) Get(key string, v any) {}
+
+type CreateOrderRequest struct {
+	Item string `+"`json:\"item\"`"+`
+	Qty  int    `+"`json:\"qty\"`"+`
+}
+
+func createOrder(w http.ResponseWriter, r *http.Request) {
+	var payload CreateOrderRequest
+	_ = json.NewDecoder(r.Body).Decode(&payload)
+}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/orders", createOrder)
+
+	var c cache
+	c.Get("/v1/not-a-route", nil)
+}
+`)
+
+	var diags []Diagnostic
+	eps, err := ScanDirWithOpts(ScanOptions{Dir: dir, UseTypes: true, Report: func(d Diagnostic) { diags = append(diags, d) }})
+	if err != nil {
+		t.Fatalf("ScanDirWithOpts err: %v", err)
+	}
+	if len(diags) > 0 {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	var orders *Endpoint
+	for i := range eps {
+		switch eps[i].Method + " " + eps[i].Path {
+		case "ANY /v1/orders":
+			orders = &eps[i]
+		case "GET /v1/not-a-route":
+			t.Errorf("cache.Get must not be detected as a route")
+		}
+	}
+	if orders == nil {
+		t.Fatalf("missing ANY /v1/orders in %v", eps)
+	}
+	for _, field := range []string{`"item":"string"`, `"qty":0`} {
+		if !strings.Contains(orders.BodyRaw, field) {
+			t.Errorf("expected body to contain %s, got %q", field, orders.BodyRaw)
+		}
+	}
+}
+
+func TestScanDirWithOpts_FallbackReportsDiagnostic(t *testing.T) {
+	dir := writeTypedModule(t, `package main
+
+import "net/http"
+
+func main() {
+	http.HandleFunc("/v1/ping", undefinedHandler)
+}
+`)
+
+	var diags []Diagnostic
+	eps, err := ScanDirWithOpts(ScanOptions{Dir: dir, UseTypes: true, Report: func(d Diagnostic) { diags = append(diags, d) }})
+	if err != nil {
+		t.Fatalf("ScanDirWithOpts err: %v", err)
+	}
+	if len(diags) == 0 {
+		t.Errorf("expected a diagnostic for the type error")
+	}
+	if len(eps) != 1 || eps[0].Path != "/v1/ping" {
+		t.Errorf("expected AST fallback to find /v1/ping, got %v", eps)
+	}
+}
+
+func TestScanDirWithOpts_MinimalBodyIsPerScan(t *testing.T) {
+	prev := globalProjectAnalysis
+	t.Cleanup(func() { globalProjectAnalysis = prev })
+	dir := writeTypedModule(t, `package main
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+type CreateOrderRequest struct {
+	Item string `+"`json:\"item\"`"+`
+	Note string `+"`json:\"note,omitempty\"`"+`
+}
+
+func createOrder(w http.ResponseWriter, r *http.Request) {
+	var order CreateOrderRequest
+	_ = json.NewDecoder(r.Body).Decode(&order)
+}
+
+func main() {
+	http.HandleFunc("/v1/orders", createOrder)
+}
+`)
+
+	minimal, err := ScanDirWithOpts(ScanOptions{Dir: dir, MinimalBody: true})
+	if err != nil || len(minimal) != 1 {
+		t.Fatalf("ScanDirWithOpts: %v, %d endpoints", err, len(minimal))
+	}
+	if minimal[0].BodyRaw != `{"item":"string"}` {
+		t.Errorf("expected omitempty fields left out, got %s", minimal[0].BodyRaw)
+	}
+
+	// the option does not outlive the scan
+	full, err := ScanDir(dir)
+	if err != nil || len(full) != 1 {
+		t.Fatalf("ScanDir: %v, %d endpoints", err, len(full))
+	}
+	if full[0].BodyRaw != `{"item":"string","note":"string"}` {
+		t.Errorf("expected every field, got %s", full[0].BodyRaw)
+	}
+}
@@ -0,0 +1,488 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldRules are the go-playground/validator rules of a struct field, read
+// from its validate tag and gin's binding tag
+type FieldRules struct {
+	Required bool
+	Format   string      // validator format tag: email, uuid, url, datetime, ...
+	Layout   string      // time layout of datetime=
+	OneOf    []string    // oneof members
+	Min      *float64    // lower bound: the value of numbers, the length of strings and slices
+	Max      *float64    // upper bound, like Min
+	MinExcl  bool        // Min is exclusive (gt)
+	MaxExcl  bool        // Max is exclusive (lt)
+	Prefix   string      // startswith=
+	Suffix   string      // endswith=
+	Contains string      // contains=
+	Dive     *FieldRules // rules of slice and map elements
+}
+
+// validatorTags are the struct tag keys holding validator rules
+var validatorTags = []string{"binding", "validate"}
+
+// ParseFieldRules reads the validator rules of a raw struct tag
+func ParseFieldRules(tag string) FieldRules {
+	var r FieldRules
+	for _, key := range validatorTags {
+		if value, ok := reflect.StructTag(tag).Lookup(key); ok {
+			r.parse(strings.Split(value, ","))
+		}
+	}
+	return r
+}
+
+func (r *FieldRules) parse(rules []string) {
+	for i := 0; i < len(rules); i++ {
+		// of alternatives (a|b) the first is used
+		rule, _, _ := strings.Cut(strings.TrimSpace(rules[i]), "|")
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			r.Dive = &FieldRules{}
+			r.Dive.parse(rules[i+1:])
+			return
+		case "keys":
+			// map key rules, up to endkeys
+			for i < len(rules) && rules[i] != "endkeys" {
+				i++
+			}
+		case "required":
+			r.Required = true
+		case "oneof":
+			r.OneOf = oneOfMembers(param)
+		case "len":
+			r.setMin(param, false)
+			r.setMax(param, false)
+		case "min", "gte":
+			r.setMin(param, false)
+		case "gt":
+			r.setMin(param, true)
+		case "max", "lte":
+			r.setMax(param, false)
+		case "lt":
+			r.setMax(param, true)
+		case "eq":
+			r.OneOf = []string{param}
+		case "startswith":
+			r.Prefix = param
+		case "endswith":
+			r.Suffix = param
+		case "contains":
+			r.Contains = param
+		case "datetime":
+			r.Format, r.Layout = name, param
+		default:
+			if _, ok := formatExamples[name]; ok {
+				r.Format = name
+			}
+		}
+	}
+}
+
+func (r *FieldRules) setMin(param string, exclusive bool) {
+	if v, err := strconv.ParseFloat(param, 64); err == nil {
+		r.Min, r.MinExcl = &v, exclusive
+	}
+}
+
+func (r *FieldRules) setMax(param string, exclusive bool) {
+	if v, err := strconv.ParseFloat(param, 64); err == nil {
+		r.Max, r.MaxExcl = &v, exclusive
+	}
+}
+
+// oneOfMembers splits oneof's space separated members; members with
+// spaces are written in single quotes
+func oneOfMembers(param string) []string {
+	var members []string
+	for param = strings.TrimSpace(param); param != ""; param = strings.TrimSpace(param) {
+		if rest, ok := strings.CutPrefix(param, "'"); ok {
+			if end := strings.IndexByte(rest, '\''); end >= 0 {
+				members = append(members, rest[:end])
+				param = rest[end+1:]
+				continue
+			}
+		}
+		member, rest, _ := strings.Cut(param, " ")
+		members = append(members, member)
+		param = rest
+	}
+	return members
+}
+
+// IsZero reports a field without rules
+func (r FieldRules) IsZero() bool {
+	return !r.Required && r.Format == "" && r.OneOf == nil && r.Min == nil && r.Max == nil &&
+		r.Prefix == "" && r.Suffix == "" && r.Contains == "" && r.Dive == nil
+}
+
+// formatExamples are values passing validator's format tags
+var formatExamples = map[string]string{
+	"email":              "user@example.com",
+	"uuid":               "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uuid4":              "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uuid_rfc4122":       "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uuid4_rfc4122":      "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+	"uuid3":              "a3bb189e-8bf9-3888-9912-ace4e6543002",
+	"uuid5":              "886313e1-3b8a-5372-9b90-0c9aee199e5d",
+	"ulid":               "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+	"url":                "https://example.com",
+	"http_url":           "https://example.com",
+	"uri":                "https://example.com/resource",
+	"ip":                 "192.0.2.1",
+	"ipv4":               "192.0.2.1",
+	"ip4_addr":           "192.0.2.1",
+	"ipv6":               "2001:db8::1",
+	"cidr":               "192.0.2.0/24",
+	"mac":                "00:00:5e:00:53:01",
+	"hostname":           "example.com",
+	"hostname_rfc1123":   "example.com",
+	"fqdn":               "example.com",
+	"e164":               "+14155552671",
+	"alpha":              "abc",
+	"alphanum":           "abc123",
+	"alphaunicode":       "abc",
+	"alphanumunicode":    "abc123",
+	"numeric":            "123",
+	"number":             "123",
+	"hexadecimal":        "1a2b",
+	"hexcolor":           "#1a2b3c",
+	"lowercase":          "string",
+	"uppercase":          "STRING",
+	"boolean":            "true",
+	"json":               "{}",
+	"base64":             "aGVsbG8gd29ybGQ=",
+	"latitude":           "52.52",
+	"longitude":          "13.405",
+	"iso3166_1_alpha2":   "US",
+	"iso3166_1_alpha3":   "USA",
+	"iso4217":            "USD",
+	"bcp47_language_tag": "en-US",
+	"timezone":           "Europe/Berlin",
+	"semver":             "1.0.0",
+	"datetime":           "2024-01-01T00:00:00Z",
+}
+
+// repeatable formats stay valid when their example is cut or repeated to
+// meet a length
+var repeatable = map[string]bool{
+	"": true, "alpha": true, "alphanum": true, "alphaunicode": true, "alphanumunicode": true,
+	"numeric": true, "number": true, "hexadecimal": true, "lowercase": true, "uppercase": true,
+}
+
+// exampleDate is the moment datetime examples show
+var exampleDate = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// stringExample returns a string passing the rules
+func (r FieldRules) stringExample() string {
+	if len(r.OneOf) > 0 {
+		return r.OneOf[0]
+	}
+	s := "string"
+	if r.Format == "datetime" && r.Layout != "" {
+		return exampleDate.Format(r.Layout)
+	}
+	if example, ok := formatExamples[r.Format]; ok {
+		s = example
+	}
+	if !repeatable[r.Format] {
+		return s
+	}
+	if r.Prefix != "" || r.Suffix != "" || r.Contains != "" {
+		s = r.Prefix + r.Contains + r.Suffix
+		if s == "" {
+			s = "string"
+		}
+		return s
+	}
+	if n, ok := r.length(len(s)); ok && n != len(s) {
+		s = strings.Repeat(s, n/len(s)+1)[:n]
+	}
+	return s
+}
+
+// length returns a length within the bounds, preferring n
+func (r FieldRules) length(n int) (int, bool) {
+	if r.Min == nil && r.Max == nil {
+		return n, false
+	}
+	if r.Min != nil {
+		if lo := int(*r.Min); n < lo || r.MinExcl && n == lo {
+			n = lo
+			if r.MinExcl {
+				n++
+			}
+		}
+	}
+	if r.Max != nil {
+		if hi := int(*r.Max); n > hi || r.MaxExcl && n == hi {
+			n = hi
+			if r.MaxExcl {
+				n--
+			}
+		}
+	}
+	if n < 0 {
+		n = 0
+	}
+	return n, true
+}
+
+// numberExample returns a number passing the rules; required numbers are
+// not zero
+func (r FieldRules) numberExample(integer bool) string {
+	if len(r.OneOf) > 0 {
+		if _, err := strconv.ParseFloat(r.OneOf[0], 64); err == nil {
+			return r.OneOf[0]
+		}
+	}
+	step := 1.0
+	if !integer {
+		step = 0.5
+	}
+	v := 0.0
+	if r.Required {
+		v = 1
+	}
+	if r.Min != nil && (v < *r.Min || r.MinExcl && v == *r.Min) {
+		v = *r.Min
+		if r.MinExcl {
+			v += step
+		}
+	}
+	if r.Max != nil && (v > *r.Max || r.MaxExcl && v == *r.Max) {
+		v = *r.Max
+		if r.MaxExcl {
+			v -= step
+		}
+	}
+	if integer {
+		return strconv.FormatInt(int64(v), 10)
+	}
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// Describe summarizes the rules for a field of a JSON Schema type
+// (string, integer, number, boolean, array, object), e.g.
+// "Required. Email address. Length 3 to 50."
+func (r FieldRules) Describe(schemaType string) string {
+	var parts []string
+	if r.Required {
+		parts = append(parts, "Required")
+	}
+	switch {
+	case r.Format == "datetime" && r.Layout != "":
+		parts = append(parts, "Date/time formatted as "+r.Layout)
+	case formatDescriptions[r.Format] != "":
+		parts = append(parts, formatDescriptions[r.Format])
+	case r.Format != "":
+		parts = append(parts, "Format: "+r.Format)
+	}
+	if len(r.OneOf) > 0 {
+		parts = append(parts, "One of: "+strings.Join(r.OneOf, ", "))
+	}
+	if bounds := r.describeBounds(schemaType); bounds != "" {
+		parts = append(parts, bounds)
+	}
+	if r.Prefix != "" {
+		parts = append(parts, fmt.Sprintf("Starts with %q", r.Prefix))
+	}
+	if r.Suffix != "" {
+		parts = append(parts, fmt.Sprintf("Ends with %q", r.Suffix))
+	}
+	if r.Contains != "" {
+		parts = append(parts, fmt.Sprintf("Contains %q", r.Contains))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return strings.Join(parts, ". ") + "."
+}
+
+var formatDescriptions = map[string]string{
+	"email":    "Email address",
+	"uuid":     "UUID",
+	"uuid3":    "UUID v3",
+	"uuid4":    "UUID v4",
+	"uuid5":    "UUID v5",
+	"url":      "URL",
+	"http_url": "HTTP URL",
+	"uri":      "URI",
+	"ip":       "IP address",
+	"ipv4":     "IPv4 address",
+	"ipv6":     "IPv6 address",
+	"hostname": "Hostname",
+	"e164":     "Phone number in E.164 format",
+	"datetime": "Date/time",
+}
+
+func (r FieldRules) describeBounds(schemaType string) string {
+	if r.Min == nil && r.Max == nil {
+		return ""
+	}
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	switch schemaType {
+	case "integer", "number":
+		var parts []string
+		if r.Min != nil {
+			if r.MinExcl {
+				parts = append(parts, "Greater than "+num(*r.Min))
+			} else {
+				parts = append(parts, "Minimum "+num(*r.Min))
+			}
+		}
+		if r.Max != nil {
+			if r.MaxExcl {
+				parts = append(parts, "Less than "+num(*r.Max))
+			} else {
+				parts = append(parts, "Maximum "+num(*r.Max))
+			}
+		}
+		return strings.Join(parts, ". ")
+	}
+
+	lo, hi := r.Min, r.Max
+	if lo != nil && r.MinExcl {
+		v := *lo + 1
+		lo = &v
+	}
+	if hi != nil && r.MaxExcl {
+		v := *hi - 1
+		hi = &v
+	}
+	unit := "Length"
+	if schemaType == "array" || schemaType == "object" {
+		unit = "Items"
+	}
+	switch {
+	case lo != nil && hi != nil && *lo == *hi:
+		return unit + " " + num(*lo)
+	case lo != nil && hi != nil:
+		return unit + " " + num(*lo) + " to " + num(*hi)
+	case lo != nil:
+		return unit + " at least " + num(*lo)
+	default:
+		return unit + " at most " + num(*hi)
+	}
+}
+
+// basicKind resolves a field type written in package pkg through named
+// types to the basic type encoding/json writes, or "" for other types
+func basicKind(goType, pkg string) string {
+	goType = strings.TrimPrefix(goType, "*")
+	for i := 0; i <= maxExampleDepth; i++ {
+		if _, ok := wellKnown(goType, pkg); ok {
+			return ""
+		}
+		if _, fn := marshalerMethod(goType, pkg); fn != nil {
+			return ""
+		}
+		switch goType {
+		case "string", "bool", "int", "int8", "int16", "int32", "int64",
+			"uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
+			"float32", "float64", "byte", "rune":
+			return goType
+		}
+		underlying, typePkg, ok := lookupFieldType(goType, pkg)
+		if !ok {
+			return ""
+		}
+		goType, pkg = underlying, typePkg
+	}
+	return ""
+}
+
+// schemaType is the JSON Schema type of a basic kind
+func schemaType(kind string) string {
+	switch kind {
+	case "":
+		return ""
+	case "string":
+		return "string"
+	case "bool":
+		return "boolean"
+	case "float32", "float64":
+		return "number"
+	}
+	return "integer"
+}
+
+// scalarExample returns an example of a basic kind passing the rules
+func (r FieldRules) scalarExample(kind string) string {
+	switch schemaType(kind) {
+	case "string":
+		return jsonString(r.stringExample())
+	case "boolean":
+		if r.Required || (len(r.OneOf) > 0 && r.OneOf[0] == "true") {
+			return "true" // required fails on false
+		}
+		return "false"
+	case "integer":
+		return r.numberExample(true)
+	case "number":
+		return r.numberExample(false)
+	}
+	return ""
+}
+
+// textExample is scalarExample as plain text, for query parameters
+func (r FieldRules) textExample(kind string) string {
+	if schemaType(kind) == "string" {
+		return r.stringExample()
+	}
+	return r.scalarExample(kind)
+}
+
+// withEnum restricts the rules of a field of a named type to the
+// constants of the type that pass them, unless oneof already does
+func (r FieldRules) withEnum(values []ConstValue, kind string) FieldRules {
+	if len(values) == 0 || len(r.OneOf) > 0 || kind == "" {
+		return r
+	}
+	for _, m := range enumMembers(values) {
+		if r.allows(m, kind) {
+			r.OneOf = append(r.OneOf, m)
+		}
+	}
+	return r
+}
+
+// allows reports whether a value of a basic kind, as text, passes the
+// required and bound rules
+func (r FieldRules) allows(value, kind string) bool {
+	var n float64
+	switch schemaType(kind) {
+	case "string":
+		n = float64(len([]rune(value)))
+		if r.Required && value == "" {
+			return false
+		}
+	case "integer", "number":
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || r.Required && v == 0 {
+			return false
+		}
+		n = v
+	default:
+		return !r.Required || value == "true"
+	}
+	if r.Min != nil && (n < *r.Min || r.MinExcl && n == *r.Min) {
+		return false
+	}
+	if r.Max != nil && (n > *r.Max || r.MaxExcl && n == *r.Max) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFieldRules(t *testing.T) {
+	ptr := func(v float64) *float64 { return &v }
+	testCases := []struct {
+		tag  string
+		want FieldRules
+	}{
+		{`json:"email" validate:"required,email"`, FieldRules{Required: true, Format: "email"}},
+		{`binding:"required,min=3,max=50"`, FieldRules{Required: true, Min: ptr(3), Max: ptr(50)}},
+		{`validate:"oneof=draft published 'in review'"`, FieldRules{OneOf: []string{"draft", "published", "in review"}}},
+		{`validate:"gt=0,lt=10"`, FieldRules{Min: ptr(0), Max: ptr(10), MinExcl: true, MaxExcl: true}},
+		{`validate:"len=2"`, FieldRules{Min: ptr(2), Max: ptr(2)}},
+		{`validate:"datetime=2006-01-02"`, FieldRules{Format: "datetime", Layout: "2006-01-02"}},
+		{`validate:"uuid4|uuid5,omitempty"`, FieldRules{Format: "uuid4"}},
+		{`validate:"required,min=1,dive,email"`, FieldRules{Required: true, Min: ptr(1), Dive: &FieldRules{Format: "email"}}},
+		{`validate:"dive,keys,min=2,endkeys,required"`, FieldRules{Dive: &FieldRules{Required: true}}},
+		{`json:"name"`, FieldRules{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.tag, func(t *testing.T) {
+			if got := ParseFieldRules(tc.tag); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFieldRulesDescribe(t *testing.T) {
+	testCases := []struct {
+		tag, schemaType, want string
+	}{
+		{`binding:"required,email"`, "string", "Required. Email address."},
+		{`binding:"required,min=3,max=50"`, "string", "Required. Length 3 to 50."},
+		{`validate:"gte=1"`, "integer", "Minimum 1."},
+		{`validate:"gt=0,lte=5"`, "number", "Greater than 0. Maximum 5."},
+		{`validate:"min=1,dive,uuid"`, "array", "Items at least 1."},
+		{`validate:"oneof=draft published"`, "string", "One of: draft, published."},
+		{`json:"id"`, "string", ""},
+	}
+	for _, tc := range testCases {
+		if got := ParseFieldRules(tc.tag).Describe(tc.schemaType); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.tag, got, tc.want)
+		}
+	}
+}
+
+func TestGenerateJSONFromProjectStruct_ValidatorRules(t *testing.T) {
+	analysis := analyzeTestProject(t, map[string]string{
+		"main.go": `package main
+
+type Status string
+
+type CreatePostRequest struct {
+	Email    string   ` + "`json:\"email\" validate:\"required,email\"`" + `
+	Username string   ` + "`json:\"username\" binding:\"required,min=8,max=50\"`" + `
+	Code     string   ` + "`json:\"code\" binding:\"len=3\"`" + `
+	Status   Status   ` + "`json:\"status\" validate:\"oneof=draft published\"`" + `
+	Page     int      ` + "`json:\"page\" validate:\"gte=1\"`" + `
+	Rating   float64  ` + "`json:\"rating\" validate:\"gt=4,lte=5\"`" + `
+	Limit    *int     ` + "`json:\"limit\" binding:\"required,max=100\"`" + `
+	Public   bool     ` + "`json:\"public\" binding:\"required\"`" + `
+	OwnerID  string   ` + "`json:\"owner_id\" validate:\"uuid4\"`" + `
+	Website  string   ` + "`json:\"website\" validate:\"omitempty,url\"`" + `
+	Birthday string   ` + "`json:\"birthday\" validate:\"datetime=2006-01-02\"`" + `
+	Tags     []string ` + "`json:\"tags\" validate:\"min=2,dive,alpha\"`" + `
+	Count    int      ` + "`json:\"count,string\" validate:\"min=5\"`" + `
+}
+`,
+	})
+
+	got := generateJSONFromProjectStruct(analysis.Structs["main.CreatePostRequest"], nil)
+	assertJSONEqual(t, got, `{
+		"email": "user@example.com",
+		"username": "stringst",
+		"code": "str",
+		"status": "draft",
+		"page": 1,
+		"rating": 4.5,
+		"limit": 1,
+		"public": true,
+		"owner_id": "3fa85f64-5717-4562-b3fc-2c963f66afa6",
+		"website": "https://example.com",
+		"birthday": "2024-01-01",
+		"tags": ["abc", "abc"],
+		"count": "5"
+	}`)
+}
@@ -0,0 +1,212 @@
+package scan
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// WellKnownType describes the JSON encoding of a type whose fields don't
+// tell its shape, such as time.Time or uuid.UUID
+type WellKnownType struct {
+	Example  string // example JSON value
+	Type     string // JSON Schema type; empty for any value
+	Format   string // JSON Schema format (optional)
+	Nullable bool   // encodes as null when not set
+}
+
+// wellKnownTypes maps type names, as written in source ("uuid.UUID"), to
+// their encoding
+var wellKnownTypes = map[string]WellKnownType{
+	"time.Time":     {Example: `"2024-01-01T00:00:00Z"`, Type: "string", Format: "date-time"},
+	"time.Duration": {Example: "1000000000", Type: "integer", Format: "int64"},
+
+	"[]byte":  {Example: `"aGVsbG8gd29ybGQ="`, Type: "string", Format: "byte"},
+	"[]uint8": {Example: `"aGVsbG8gd29ybGQ="`, Type: "string", Format: "byte"},
+
+	"json.RawMessage": {Example: "{}"},
+	"json.Number":     {Example: "0", Type: "number"},
+
+	"uuid.UUID":     {Example: `"3fa85f64-5717-4562-b3fc-2c963f66afa6"`, Type: "string", Format: "uuid"},
+	"uuid.NullUUID": {Example: `"3fa85f64-5717-4562-b3fc-2c963f66afa6"`, Type: "string", Format: "uuid", Nullable: true},
+
+	"decimal.Decimal":     {Example: `"10.50"`, Type: "string", Format: "decimal"},
+	"decimal.NullDecimal": {Example: `"10.50"`, Type: "string", Format: "decimal", Nullable: true},
+
+	"netip.Addr":         {Example: `"192.0.2.1"`, Type: "string", Format: "ipv4"},
+	"netip.AddrPort":     {Example: `"192.0.2.1:8080"`, Type: "string"},
+	"netip.Prefix":       {Example: `"192.0.2.0/24"`, Type: "string"},
+	"net.IP":             {Example: `"192.0.2.1"`, Type: "string", Format: "ipv4"},
+	"big.Int":            {Example: "0", Type: "integer"},
+	"big.Float":          {Example: `"0.0"`, Type: "string"},
+	"primitive.ObjectID": {Example: `"507f1f77bcf86cd799439011"`, Type: "string"},
+
+	// gopkg.in/guregu/null: nullable scalars
+	"null.String": {Example: `"string"`, Type: "string", Nullable: true},
+	"null.Int":    {Example: "0", Type: "integer", Nullable: true},
+	"null.Float":  {Example: "0.0", Type: "number", Nullable: true},
+	"null.Bool":   {Example: "false", Type: "boolean", Nullable: true},
+	"null.Time":   {Example: `"2024-01-01T00:00:00Z"`, Type: "string", Format: "date-time", Nullable: true},
+
+	// database/sql's Null types have no JSON methods, so encoding/json
+	// writes the struct as is
+	"sql.NullString":  {Example: `{"String":"string","Valid":true}`, Type: "object"},
+	"sql.NullInt64":   {Example: `{"Int64":0,"Valid":true}`, Type: "object"},
+	"sql.NullInt32":   {Example: `{"Int32":0,"Valid":true}`, Type: "object"},
+	"sql.NullInt16":   {Example: `{"Int16":0,"Valid":true}`, Type: "object"},
+	"sql.NullByte":    {Example: `{"Byte":0,"Valid":true}`, Type: "object"},
+	"sql.NullFloat64": {Example: `{"Float64":0.0,"Valid":true}`, Type: "object"},
+	"sql.NullBool":    {Example: `{"Bool":false,"Valid":true}`, Type: "object"},
+	"sql.NullTime":    {Example: `{"Time":"2024-01-01T00:00:00Z","Valid":true}`, Type: "object"},
+}
+
+// RegisterWellKnownType adds or replaces the encoding of a type named as
+// in source, e.g. "money.Money"
+func RegisterWellKnownType(name string, t WellKnownType) {
+	wellKnownTypes[name] = t
+}
+
+// LookupWellKnownType returns the registered encoding of a type name
+func LookupWellKnownType(name string) (WellKnownType, bool) {
+	t, ok := wellKnownTypes[strings.TrimLeft(name, "*")]
+	return t, ok
+}
+
+// LoadWellKnownTypes registers the types of a JSON file mapping type names
+// to their example and schema:
+//
+//	{"money.Money": {"example": "10.00 EUR", "type": "string"}}
+//
+// The type is inferred from the example when omitted.
+func LoadWellKnownTypes(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var entries map[string]struct {
+		Example  json.RawMessage `json:"example"`
+		Type     string          `json:"type"`
+		Format   string          `json:"format"`
+		Nullable bool            `json:"nullable"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return err
+	}
+	for name, e := range entries {
+		t := WellKnownType{Type: e.Type, Format: e.Format, Nullable: e.Nullable}
+		if len(e.Example) > 0 {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, e.Example); err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			t.Example = compact.String()
+		}
+		if t.Type == "" {
+			t.Type = jsonSchemaType(t.Example)
+		}
+		if t.Example == "" {
+			t.Example = exampleForSchemaType(t.Type)
+		}
+		RegisterWellKnownType(name, t)
+	}
+	return nil
+}
+
+// jsonSchemaType is the JSON Schema type of an example value
+func jsonSchemaType(example string) string {
+	if example == "" {
+		return ""
+	}
+	switch example[0] {
+	case '"':
+		return "string"
+	case '{':
+		return "object"
+	case '[':
+		return "array"
+	case 't', 'f':
+		return "boolean"
+	case 'n':
+		return ""
+	}
+	if strings.ContainsAny(example, ".eE") {
+		return "number"
+	}
+	return "integer"
+}
+
+func exampleForSchemaType(schemaType string) string {
+	switch schemaType {
+	case "string":
+		return `"string"`
+	case "integer":
+		return "0"
+	case "number":
+		return "0.0"
+	case "boolean":
+		return "false"
+	case "array":
+		return "[]"
+	case "object":
+		return "{}"
+	}
+	return "null"
+}
+
+// wellKnown resolves a field type written in package pkg against the
+// registry; types of the project itself may be registered unqualified
+// in their own package
+func wellKnown(goType, pkg string) (WellKnownType, bool) {
+	if t, ok := LookupWellKnownType(goType); ok {
+		return t, true
+	}
+	if pkg != "" && !strings.ContainsAny(goType, ".[") {
+		return LookupWellKnownType(pkg + "." + goType)
+	}
+	return WellKnownType{}, false
+}
+
+// Encoding methods a project type can define to replace its fields in JSON
+const (
+	marshalJSON = "MarshalJSON"
+	marshalText = "MarshalText"
+)
+
+// marshalerMethod returns the project type a field type names, written in
+// package pkg, and its MarshalJSON or MarshalText method if it has one
+func marshalerMethod(goType, pkg string) (string, *FunctionInfo) {
+	if globalProjectAnalysis == nil || goType == "" || strings.ContainsAny(goType, "[]") {
+		return "", nil
+	}
+	key := goType
+	if !strings.Contains(goType, ".") {
+		if pkg == "" {
+			return "", nil
+		}
+		key = pkg + "." + goType
+	}
+	for _, method := range []string{marshalJSON, marshalText} {
+		if fn, ok := globalProjectAnalysis.Functions[key+"."+method]; ok && fn.IsMethod {
+			return key, fn
+		}
+	}
+	return "", nil
+}
+
+// reportOpaque reports, once per scan, that an example was built for a
+// type with its own MarshalJSON
+func (ex *exampleOptions) reportOpaque(key string, fn *FunctionInfo) {
+	if ex.report == nil || ex.seen[key] {
+		return
+	}
+	if ex.seen == nil {
+		ex.seen = make(map[string]bool)
+	}
+	ex.seen[key] = true
+	ex.report(Diagnostic{
+		Pos:     fn.File,
+		Message: fmt.Sprintf("%s implements json.Marshaler; its JSON shape is opaque, register it as a well-known type for a real example", key),
+	})
+}